@@ -0,0 +1,49 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+// Adapters of the validated file extensions, the html files are parsed without adapter
+var fileAdapters = map[string]tableparser.Adapter{
+	".html":       nil,
+	".htm":        nil,
+	".md":         tableparser.MarkdownAdapter,
+	".markdown":   tableparser.MarkdownAdapter,
+	".tmpl":       tableparser.GoTemplateAdapter,
+	".gohtml":     tableparser.GoTemplateAdapter,
+	".hbs":        tableparser.HandlebarsAdapter,
+	".handlebars": tableparser.HandlebarsAdapter,
+}
+
+// Adapters of the template syntax of the html files, see Config.Template
+var templateAdapters = map[string]tableparser.Adapter{
+	"go":         tableparser.GoTemplateAdapter,
+	"handlebars": tableparser.HandlebarsAdapter,
+}
+
+// isValidatedFile check if the file extension is an html, markdown or template extension
+func isValidatedFile(path string) bool {
+	var _, exists = fileAdapters[strings.ToLower(filepath.Ext(path))]
+	return exists
+}
+
+// fileAdapter return the adapter of the file, nil when the file is html without template
+func fileAdapter(path string, config Config) tableparser.Adapter {
+	var adapter = fileAdapters[strings.ToLower(filepath.Ext(path))]
+	if adapter == nil {
+		adapter = templateAdapters[config.Template]
+	}
+	return adapter
+}
+
+// adaptSource convert the content of the file to html, the positions in the html are the positions in the file
+func adaptSource(path string, source string, config Config) string {
+	if adapter := fileAdapter(path, config); adapter != nil {
+		return adapter(source)
+	}
+	return source
+}
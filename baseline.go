@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+// Version of the baseline file format
+const baselineVersion = 1
+
+// Baseline is the list of the known problems, only the new problems are reported
+type Baseline struct {
+	Version  int               `json:"version"`
+	Findings []BaselineFinding `json:"findings"`
+}
+
+// BaselineFinding is a known problem, it is identified by the file, the table fingerprint and the rule code
+type BaselineFinding struct {
+	File  string `json:"file"`
+	Table string `json:"table"`
+	Code  int    `json:"code"`
+	Count int    `json:"count"`
+}
+
+type baselineKey struct {
+	file  string
+	table string
+	code  int
+}
+
+// loadBaseline read the baseline file
+func loadBaseline(path string) (Baseline, error) {
+	var baseline = Baseline{}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return baseline, err
+	}
+
+	err = json.Unmarshal(content, &baseline)
+	return baseline, err
+}
+
+// newBaseline record the problems of the results
+func newBaseline(results []FileResult) Baseline {
+	var counts = map[baselineKey]int{}
+	for _, result := range results {
+		for _, table := range result.Tables {
+			for _, diag := range table.Diagnostics {
+				counts[baselineKey{file: baselinePath(result.Path), table: table.Fingerprint, code: diag.Rule.Code}]++
+			}
+		}
+	}
+
+	var baseline = Baseline{
+		Version:  baselineVersion,
+		Findings: []BaselineFinding{},
+	}
+	for key, count := range counts {
+		baseline.Findings = append(baseline.Findings, BaselineFinding{
+			File:  key.file,
+			Table: key.table,
+			Code:  key.code,
+			Count: count,
+		})
+	}
+
+	// Keep the file stable between the runs
+	sort.Slice(baseline.Findings, func(i, j int) bool {
+		var a, b = baseline.Findings[i], baseline.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Code < b.Code
+	})
+
+	return baseline
+}
+
+// save write the baseline file
+func (b Baseline) save(path string) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// baselineFilter remove the known problems of the baseline, each known problem is removed once
+type baselineFilter struct {
+	remaining map[baselineKey]int
+}
+
+// newFilter return the filter of the baseline, it can be used on the results one file or one table at a time
+func (b Baseline) newFilter() *baselineFilter {
+	var filter = &baselineFilter{remaining: map[baselineKey]int{}}
+	for _, finding := range b.Findings {
+		filter.remaining[baselineKey{file: finding.File, table: finding.Table, code: finding.Code}] += finding.Count
+	}
+	return filter
+}
+
+// filter remove the known problems from the results, it return the number of problems removed
+func (b Baseline) filter(results []FileResult) int {
+	return b.newFilter().filter(results)
+}
+
+// filter remove the known problems from the results, it return the number of problems removed
+func (f *baselineFilter) filter(results []FileResult) int {
+	var remaining = f.remaining
+	var known = 0
+	for i := range results {
+		for j := range results[i].Tables {
+			var table = &results[i].Tables[j]
+			var diagnostics = tableparser.Diagnostics{}
+			for _, diag := range table.Diagnostics {
+				var key = baselineKey{file: baselinePath(results[i].Path), table: table.Fingerprint, code: diag.Rule.Code}
+				if remaining[key] > 0 {
+					remaining[key]--
+					known++
+					continue
+				}
+				diagnostics = append(diagnostics, diag)
+			}
+			table.Diagnostics = diagnostics
+		}
+	}
+	return known
+}
+
+// applyBaseline record the baseline file when it does not exist or when update is set,
+// then the known problems are removed from the results
+func applyBaseline(path string, update bool, results []FileResult) (known int, err error) {
+	var baseline Baseline
+	if _, err = os.Stat(path); os.IsNotExist(err) || update {
+		baseline = newBaseline(results)
+		err = baseline.save(path)
+	} else {
+		baseline, err = loadBaseline(path)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return baseline.filter(results), nil
+}
+
+// writeKnownProblems write the number of problems removed by the baseline on the error output
+func writeKnownProblems(known int, path string) {
+	if known > 0 {
+		fmt.Fprintf(os.Stderr, "%d known problems in the baseline %s\n", known, path)
+	}
+}
+
+// baselinePath return the path in the same form on all the systems
+func baselinePath(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
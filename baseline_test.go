@@ -0,0 +1,128 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+// tableFindings return a table result with a problem for each code
+func tableFindings(fingerprint string, codes ...int) TableResult {
+	var table = TableResult{
+		Fingerprint: fingerprint,
+		Diagnostics: tableparser.Diagnostics{},
+	}
+	for _, code := range codes {
+		table.Diagnostics = append(table.Diagnostics, &tableparser.Diagnostic{Rule: tableparser.Rule{Code: code}})
+	}
+	return table
+}
+
+// resultCodes return the codes of the problems of each table
+func resultCodes(results []FileResult) [][]int {
+	var codes = [][]int{}
+	for _, result := range results {
+		for _, table := range result.Tables {
+			var tableCodes = []int{}
+			for _, diag := range table.Diagnostics {
+				tableCodes = append(tableCodes, diag.Rule.Code)
+			}
+			codes = append(codes, tableCodes)
+		}
+	}
+	return codes
+}
+
+func TestNewBaseline(t *testing.T) {
+	var results = []FileResult{
+		{Path: "./pages/b.html", Tables: []TableResult{tableFindings("t1", 23)}},
+		{Path: "pages/a.html", Tables: []TableResult{tableFindings("t2", 23, 16, 16), tableFindings("t1")}},
+	}
+
+	// The problems are counted by file, table and code, in a stable order
+	var want = []BaselineFinding{
+		{File: "pages/a.html", Table: "t2", Code: 16, Count: 2},
+		{File: "pages/a.html", Table: "t2", Code: 23, Count: 1},
+		{File: "pages/b.html", Table: "t1", Code: 23, Count: 1},
+	}
+	var baseline = newBaseline(results)
+	if baseline.Version != baselineVersion || reflect.DeepEqual(baseline.Findings, want) == false {
+		t.Errorf("baseline = %+v, want %+v", baseline, want)
+	}
+}
+
+func TestBaselineFilter(t *testing.T) {
+	var baseline = Baseline{
+		Version: baselineVersion,
+		Findings: []BaselineFinding{
+			{File: "a.html", Table: "t1", Code: 16, Count: 2},
+			{File: "a.html", Table: "t2", Code: 23, Count: 1},
+		},
+	}
+
+	// Each known problem is removed once, the problems of an other table are kept
+	var filter = baseline.newFilter()
+	var results = []FileResult{{Path: "a.html", Tables: []TableResult{tableFindings("t1", 16, 23, 16, 16), tableFindings("t3", 23)}}}
+	if known := filter.filter(results); known != 2 {
+		t.Errorf("known problems = %d, want 2", known)
+	}
+	if codes := resultCodes(results); reflect.DeepEqual(codes, [][]int{{23, 16}, {23}}) == false {
+		t.Errorf("codes = %v, want [[23 16] [23]]", codes)
+	}
+
+	// The filter is used one table at a time by the stream mode, the counts are shared between the calls
+	results = []FileResult{{Path: "a.html", Tables: []TableResult{tableFindings("t1", 16), tableFindings("t2", 23, 23)}}}
+	if known := filter.filter(results); known != 1 {
+		t.Errorf("known problems = %d, want 1", known)
+	}
+	if codes := resultCodes(results); reflect.DeepEqual(codes, [][]int{{16}, {23}}) == false {
+		t.Errorf("codes = %v, want [[16] [23]]", codes)
+	}
+}
+
+func TestApplyBaseline(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "baseline.json")
+	var newResults = func(codes ...int) []FileResult {
+		return []FileResult{{Path: "a.html", Tables: []TableResult{tableFindings("t1", codes...)}}}
+	}
+
+	// Without baseline file, the problems are recorded and all of them are known
+	var results = newResults(16, 23)
+	known, err := applyBaseline(path, false, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := resultCodes(results); known != 2 || reflect.DeepEqual(codes, [][]int{{}}) == false {
+		t.Errorf("first run: known = %d, codes = %v, want 2 and [[]]", known, codes)
+	}
+
+	// The baseline is read, only the new problem is reported
+	results = newResults(16, 23, 16)
+	known, err = applyBaseline(path, false, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := resultCodes(results); known != 2 || reflect.DeepEqual(codes, [][]int{{16}}) == false {
+		t.Errorf("second run: known = %d, codes = %v, want 2 and [[16]]", known, codes)
+	}
+
+	// --update-baseline record the file again with the current problems
+	results = newResults(16, 16)
+	known, err = applyBaseline(path, true, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := resultCodes(results); known != 2 || reflect.DeepEqual(codes, [][]int{{}}) == false {
+		t.Errorf("update: known = %d, codes = %v, want 2 and [[]]", known, codes)
+	}
+	baseline, err := loadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want = []BaselineFinding{{File: "a.html", Table: "t1", Code: 16, Count: 2}}
+	if reflect.DeepEqual(baseline.Findings, want) == false {
+		t.Errorf("updated baseline = %+v, want %+v", baseline.Findings, want)
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"gopkg.in/yaml.v2"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+// Names of the configuration file looked up in the working directory
+var configFileNames = []string{".tablevalidator.yaml", ".tablevalidator.yml", ".tablevalidator.json"}
+
+// Config is the project configuration of the validator
+type Config struct {
+	// Rules set the severity of a rule by code or id: "off", "on", "info", "warning" or "error",
+	// "on" keep the default severity of the rule
+	Rules map[string]string `yaml:"rules" json:"rules"`
+	// Ignore are the file patterns that are not validated
+	Ignore []string `yaml:"ignore" json:"ignore"`
+	// Selector is the css selector of the validated tables, all the tables by default
+	Selector string `yaml:"selector" json:"selector"`
+	// Format is the output format: "text", "json" or "html"
+	Format string `yaml:"format" json:"format"`
+	// GroupBy group the text report by "file" or by WCAG "criterion"
+	GroupBy string `yaml:"groupBy" json:"groupBy"`
+	// FailOn is the lowest severity that make the run fail, "error" by default
+	FailOn string `yaml:"failOn" json:"failOn"`
+	// Suppress are the rules suppressed by table fingerprint, an empty list suppress all the rules
+	Suppress map[string][]string `yaml:"suppress" json:"suppress"`
+	// Baseline is the file of the known problems
+	Baseline string `yaml:"baseline" json:"baseline"`
+	// Lang is the language of the messages: "en", "fr" or the language of the Messages file
+	Lang string `yaml:"lang" json:"lang"`
+	// Messages is a json file of messages by rule id, for an other language or to replace the messages
+	Messages string `yaml:"messages" json:"messages"`
+	// Template is the syntax of the template actions in the html files: "go" or "handlebars", none by default
+	Template string `yaml:"template" json:"template"`
+	// Parser are the table parser options
+	Parser ParserConfig `yaml:"parser" json:"parser"`
+}
+
+// ParserConfig are the options of the table parser, see tableparser.Options
+type ParserConfig struct {
+	Hassum           string `yaml:"hassum" json:"hassum"`
+	FixDescribedBy   bool   `yaml:"fixDescribedBy" json:"fixDescribedBy"`
+	IgnoreLayoutCell bool   `yaml:"ignoreLayoutCell" json:"ignoreLayoutCell"`
+	// MaxRows, MaxColumns and MaxSlots replace the default limits when they are set
+	MaxRows    int `yaml:"maxRows" json:"maxRows"`
+	MaxColumns int `yaml:"maxColumns" json:"maxColumns"`
+	MaxSlots   int `yaml:"maxSlots" json:"maxSlots"`
+	// Lenient ignore the script-supporting, form and hidden input elements in the table structure
+	Lenient bool `yaml:"lenient" json:"lenient"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Rules:  map[string]string{},
+		Format: "text",
+		FailOn: "error",
+	}
+}
+
+// findConfig return the path of the configuration file in the directory, or an empty string
+func findConfig(dir string) string {
+	for _, name := range configFileNames {
+		var path = filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && info.IsDir() == false {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadConfig read the configuration file, the format is based on the file extension
+func loadConfig(path string) (Config, error) {
+	var config = defaultConfig()
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(content, &config)
+	} else {
+		err = yaml.Unmarshal(content, &config)
+	}
+	if err != nil {
+		return config, errors.New(path + ": " + err.Error())
+	}
+
+	if len(config.Format) == 0 {
+		config.Format = "text"
+	}
+	if len(config.FailOn) == 0 {
+		config.FailOn = "error"
+	}
+
+	return config, config.validate()
+}
+
+// validate check the values of the configuration
+func (c Config) validate() error {
+	for key, value := range c.Rules {
+		if _, err := ruleCode(key); err != nil {
+			return err
+		}
+		if _, ok := tableparser.ParseSeverity(value); ok == false && value != "off" && value != "on" {
+			return errors.New("unknown severity \"" + value + "\" for the rule " + key)
+		}
+	}
+
+	for fingerprint, rules := range c.Suppress {
+		for _, rule := range rules {
+			if _, err := ruleCode(rule); err != nil {
+				return errors.New(err.Error() + " in the suppressions of the table " + fingerprint)
+			}
+		}
+	}
+
+	if _, ok := tableparser.ParseHassumMode(c.Parser.Hassum); ok == false {
+		return errors.New("unknown hassum mode \"" + c.Parser.Hassum + "\"")
+	}
+
+	if len(c.Selector) != 0 {
+		if _, err := cascadia.Compile(c.Selector); err != nil {
+			return errors.New("invalid selector \"" + c.Selector + "\": " + err.Error())
+		}
+	}
+
+	if len(c.Lang) != 0 && len(c.Messages) == 0 && tableparser.HasLang(c.Lang) == false {
+		return errors.New("unknown language \"" + c.Lang + "\", set the messages file of the language")
+	}
+
+	if _, exists := templateAdapters[c.Template]; len(c.Template) != 0 && exists == false {
+		return errors.New("unknown template syntax \"" + c.Template + "\"")
+	}
+
+	if c.GroupBy != "" && c.GroupBy != "file" && c.GroupBy != "criterion" {
+		return errors.New("unknown report grouping \"" + c.GroupBy + "\", use file or criterion")
+	}
+
+	if _, ok := tableparser.ParseSeverity(c.FailOn); ok == false {
+		return errors.New("unknown severity \"" + c.FailOn + "\" for failOn")
+	}
+
+	return nil
+}
+
+// ruleCode return the code of a rule from his code or his id
+func ruleCode(key string) (int, error) {
+	if code, err := strconv.Atoi(key); err == nil {
+		if _, ok := tableparser.RuleByCode(code); ok == true {
+			return code, nil
+		}
+	} else if rule, ok := tableparser.RuleByID(key); ok == true {
+		return rule.Code, nil
+	}
+	return 0, errors.New("unknown rule \"" + key + "\"")
+}
+
+// parserOptions return the table parser options of the configuration
+func (c Config) parserOptions() tableparser.Options {
+	var options = tableparser.DefaultOptions()
+
+	options.Hassum, _ = tableparser.ParseHassumMode(c.Parser.Hassum)
+	options.FixDescribedBy = c.Parser.FixDescribedBy
+	options.IgnoreLayoutCell = c.Parser.IgnoreLayoutCell
+	options.Lenient = c.Parser.Lenient
+	options.Severities = map[int]tableparser.Severity{}
+	options.Suppressions = c.Suppress
+	if c.Parser.MaxRows > 0 {
+		options.MaxRows = c.Parser.MaxRows
+	}
+	if c.Parser.MaxColumns > 0 {
+		options.MaxColumns = c.Parser.MaxColumns
+	}
+	if c.Parser.MaxSlots > 0 {
+		options.MaxSlots = c.Parser.MaxSlots
+	}
+	if len(c.Lang) != 0 {
+		options.Lang = c.Lang
+	}
+
+	for key, value := range c.Rules {
+		var code, _ = ruleCode(key)
+		if value == "off" {
+			options.DisabledRules = append(options.DisabledRules, code)
+		} else if value == "on" {
+			// The rule is reported with his default severity, like a rule that is not in the configuration
+			options.Severities[code] = tableparser.SeverityDefault
+		} else if severity, ok := tableparser.ParseSeverity(value); ok == true {
+			options.Severities[code] = severity
+		}
+	}
+
+	return options
+}
+
+// loadMessages register the messages file for the language of the configuration
+func (c Config) loadMessages() error {
+	if len(c.Messages) == 0 {
+		return nil
+	}
+
+	file, err := os.Open(c.Messages)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	catalog, err := tableparser.LoadCatalog(file)
+	if err != nil {
+		return errors.New(c.Messages + ": " + err.Error())
+	}
+
+	var lang = c.Lang
+	if len(lang) == 0 {
+		lang = tableparser.DefaultLang
+	}
+	tableparser.RegisterCatalog(lang, catalog)
+	return nil
+}
+
+// failSeverity return the lowest severity that make the run fail
+func (c Config) failSeverity() tableparser.Severity {
+	var severity, _ = tableparser.ParseSeverity(c.FailOn)
+	if severity == tableparser.SeverityDefault {
+		return tableparser.SeverityError
+	}
+	return severity
+}
+
+// isIgnored check if the path match one of the ignore patterns,
+// a pattern match the path, his base name or one of his parent directories
+func (c Config) isIgnored(path string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+
+	for _, pattern := range c.Ignore {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/**")
+		pattern = strings.TrimSuffix(pattern, "/")
+
+		for dir := path; dir != "." && dir != "/" && len(dir) != 0; dir = filepath.ToSlash(filepath.Dir(dir)) {
+			if matched, _ := filepath.Match(pattern, dir); matched == true {
+				return true
+			}
+			if matched, _ := filepath.Match(pattern, filepath.Base(dir)); matched == true {
+				return true
+			}
+		}
+	}
+	return false
+}
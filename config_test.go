@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var tests = []struct {
+		name   string
+		config func(config *Config)
+		err    string
+	}{
+		{"default", func(config *Config) {}, ""},
+		{"rule severity", func(config *Config) { config.Rules["row-width"] = "warning" }, ""},
+		{"rule off", func(config *Config) { config.Rules["16"] = "off" }, ""},
+		{"rule on", func(config *Config) { config.Rules["16"] = "on" }, ""},
+		{"unknown severity", func(config *Config) { config.Rules["16"] = "fatal" }, "unknown severity"},
+		{"unknown rule", func(config *Config) { config.Rules["not-a-rule"] = "error" }, "unknown rule"},
+		{"unknown hassum", func(config *Config) { config.Parser.Hassum = "sometimes" }, "unknown hassum mode"},
+		{"invalid selector", func(config *Config) { config.Selector = "table[" }, "invalid selector"},
+		{"group by criterion", func(config *Config) { config.GroupBy = "criterion" }, ""},
+		{"unknown grouping", func(config *Config) { config.GroupBy = "table" }, "unknown report grouping"},
+		{"unknown failOn", func(config *Config) { config.FailOn = "fatal" }, "for failOn"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var config = defaultConfig()
+			test.config(&config)
+			var err = config.validate()
+			if len(test.err) == 0 {
+				if err != nil {
+					t.Errorf("err = %v", err)
+				}
+				return
+			}
+			if err == nil || strings.Contains(err.Error(), test.err) == false {
+				t.Errorf("err = %v, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestConfigParserOptions(t *testing.T) {
+	var config = defaultConfig()
+	config.Rules["16"] = "off"
+	config.Rules["row-width"] = "on"
+	config.Rules["23"] = "info"
+	var options = config.parserOptions()
+
+	// "on" keep the default severity, the rule is not disabled
+	if len(options.DisabledRules) != 1 || options.DisabledRules[0] != 16 {
+		t.Errorf("disabled rules = %v, want [16]", options.DisabledRules)
+	}
+	var rowWidth, _ = ruleCode("row-width")
+	if severity, exists := options.Severities[rowWidth]; exists == false || severity != tableparser.SeverityDefault {
+		t.Errorf("row-width severity = %v, want the default severity", severity)
+	}
+	if options.Severities[23] != tableparser.SeverityInfo {
+		t.Errorf("23 severity = %v, want info", options.Severities[23])
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+// Number of rows and columns drawn around the problem cell, the others are skipped
+const gridContextRows = 3
+const gridContextColumns = 5
+
+// Short name of the cell types, in the order of the tableparser.Type values
+var gridRoleNames = []string{"?", "hdr", "dat", "sum", "key", "dsc", "lay", "grp"}
+
+// ANSI colors of the cell types and of the problem cell
+var gridRoleColors = []string{"", "\x1b[36m", "", "\x1b[35m", "\x1b[33m", "\x1b[34m", "\x1b[90m", "\x1b[32m"}
+
+const colorProblem = "\x1b[1;41;97m"
+const colorReset = "\x1b[0m"
+
+// gridLegend explain the short names of the cell types
+const gridLegend = "cells: hdr header, dat data, sum summary, key key, dsc description, lay layout, grp group header, ? not parsed; the problem is marked with > <"
+
+// isTerminal check if the file is a terminal, the colors are turned off by the NO_COLOR environment variable
+func isTerminal(file *os.File) bool {
+	if len(os.Getenv("NO_COLOR")) != 0 {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// writeGrid draw the slot grid of the table, each cell show his element and his type,
+// the slots of the element of the diagnostic are marked
+func writeGrid(w io.Writer, grid [][]tableparser.Cell, diag *tableparser.Diagnostic, color bool) {
+	if len(grid) == 0 {
+		return
+	}
+
+	var width = 0
+	for _, line := range grid {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+
+	// Only the rows and the columns around the problem are drawn for the big tables
+	var problemRow, problemCol = findProblemCell(grid, diag)
+	var first, last = gridWindow(problemRow, len(grid), gridContextRows)
+	var firstCol, lastCol = gridWindow(problemCol, width, gridContextColumns)
+
+	var border = "     +" + strings.Repeat("--------+", lastCol-firstCol+1)
+	fmt.Fprintln(w, border)
+	if first > 0 {
+		fmt.Fprintf(w, "     | ... %d rows\n", first)
+	}
+	for y := first; y <= last; y++ {
+		var line = fmt.Sprintf("%4d |", y+1)
+		if firstCol > 0 {
+			line += fmt.Sprintf(" ... %d columns |", firstCol)
+		}
+		for x := firstCol; x <= lastCol; x++ {
+			var slot = tableparser.Cell{}
+			if x < len(grid[y]) {
+				slot = grid[y][x]
+			}
+			line += formatSlot(slot, isProblemCell(slot, diag), color) + "|"
+		}
+		if lastCol < width-1 {
+			line += fmt.Sprintf(" ... %d columns", width-1-lastCol)
+		}
+		fmt.Fprintln(w, line)
+	}
+	if last < len(grid)-1 {
+		fmt.Fprintf(w, "     | ... %d rows\n", len(grid)-1-last)
+	}
+	fmt.Fprintln(w, border)
+}
+
+// formatSlot write a slot in 8 characters, like " th:hdr "
+func formatSlot(slot tableparser.Cell, problem bool, color bool) string {
+	var text = "   --   "
+	var role = 0
+	if slot.Selection() != nil {
+		role = int(slot.Type())
+		if role < 0 || role >= len(gridRoleNames) {
+			role = 0
+		}
+		text = fmt.Sprintf(" %s:%-3s ", goquery.NodeName(slot.Selection()), gridRoleNames[role])
+	}
+
+	if problem {
+		text = ">" + text[1:len(text)-1] + "<"
+	}
+	if color == false {
+		return text
+	}
+	if problem {
+		return colorProblem + text + colorReset
+	}
+	if len(gridRoleColors[role]) != 0 && slot.Selection() != nil {
+		return gridRoleColors[role] + text + colorReset
+	}
+	return text
+}
+
+// isProblemCell check if the slot is the element of the diagnostic, or is inside it like a row
+func isProblemCell(slot tableparser.Cell, diag *tableparser.Diagnostic) bool {
+	if diag == nil || diag.Selection == nil || diag.Selection.Length() == 0 || slot.Selection() == nil {
+		return false
+	}
+	if goquery.NodeName(diag.Selection) == "table" {
+		return false
+	}
+	return slot.Selection().IsSelection(diag.Selection) || slot.Selection().ParentsFiltered("*").IsSelection(diag.Selection)
+}
+
+// findProblemCell return the position of the first problem slot, or -1
+func findProblemCell(grid [][]tableparser.Cell, diag *tableparser.Diagnostic) (row int, col int) {
+	for y, line := range grid {
+		for x, slot := range line {
+			if isProblemCell(slot, diag) {
+				return y, x
+			}
+		}
+	}
+	return -1, -1
+}
+
+// gridWindow return the first and the last position drawn around the problem position,
+// the window is at the start when there is no problem position
+func gridWindow(position int, length int, context int) (first int, last int) {
+	if position < 0 {
+		return 0, minInt(length-1, 2*context)
+	}
+	return maxInt(0, position-context), minInt(length-1, position+context)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
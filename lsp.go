@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+// Message of the language server protocol, a request, a response or a notification
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *lspError        `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspCodeAction struct {
+	Title string `json:"title"`
+	Kind  string `json:"kind"`
+	Edit  struct {
+		Changes map[string][]lspTextEdit `json:"changes"`
+	} `json:"edit"`
+}
+
+type lspTextDocument struct {
+	URI     string `json:"uri"`
+	Text    string `json:"text"`
+	Version int    `json:"version"`
+}
+
+// JSON-RPC error codes
+const (
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+)
+
+// lspServer publish the table diagnostics of the open documents, the documents are fully synchronized
+type lspServer struct {
+	config    Config
+	reader    *bufio.Reader
+	writer    io.Writer
+	documents map[string]string
+	shutdown  bool
+}
+
+// runLSP is the lsp subcommand, the server speak over the standard input and output
+func runLSP(args []string) {
+	var flags = flag.NewFlagSet("lsp", flag.ExitOnError)
+	var configPath = flags.String("config", "", "configuration file, .tablevalidator.yaml or .tablevalidator.json in the working directory by default")
+	flags.Parse(args)
+
+	var config = defaultConfig()
+	if len(*configPath) == 0 {
+		*configPath = findConfig(".")
+	}
+	if len(*configPath) != 0 {
+		var err error
+		config, err = loadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if err := config.loadMessages(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var server = &lspServer{
+		config:    config,
+		reader:    bufio.NewReader(os.Stdin),
+		writer:    os.Stdout,
+		documents: map[string]string{},
+	}
+	if err := server.run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run read the messages until the exit notification or the end of the input
+func (s *lspServer) run() error {
+	for {
+		message, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if message.Method == "exit" {
+			if s.shutdown == false {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+		s.handle(message)
+	}
+}
+
+// readMessage read the Content-Length header and the json content
+func (s *lspServer) readMessage() (lspMessage, error) {
+	var message = lspMessage{}
+	var length = -1
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return message, err
+		}
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(line), "content-length:") {
+			length, err = strconv.Atoi(strings.TrimSpace(line[len("content-length:"):]))
+			if err != nil {
+				return message, err
+			}
+		}
+	}
+	if length < 0 {
+		return message, errors.New("missing Content-Length header")
+	}
+
+	var content = make([]byte, length)
+	if _, err := io.ReadFull(s.reader, content); err != nil {
+		return message, err
+	}
+	return message, json.Unmarshal(content, &message)
+}
+
+func (s *lspServer) writeMessage(message lspMessage) {
+	message.JSONRPC = "2.0"
+	content, err := json.Marshal(message)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(content), content)
+}
+
+func (s *lspServer) reply(id *json.RawMessage, result interface{}) {
+	if result == nil {
+		// The result is required in a response, even when it is null
+		result = json.RawMessage("null")
+	}
+	s.writeMessage(lspMessage{ID: id, Result: result})
+}
+
+func (s *lspServer) replyError(id *json.RawMessage, code int, message string) {
+	s.writeMessage(lspMessage{ID: id, Error: &lspError{Code: code, Message: message}})
+}
+
+func (s *lspServer) notify(method string, params interface{}) {
+	content, _ := json.Marshal(params)
+	s.writeMessage(lspMessage{Method: method, Params: content})
+}
+
+func (s *lspServer) handle(message lspMessage) {
+	switch message.Method {
+	case "initialize":
+		s.reply(message.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				// Full synchronization of the documents
+				"textDocumentSync":   1,
+				"codeActionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "tablevalidator"},
+		})
+	case "shutdown":
+		s.shutdown = true
+		s.reply(message.ID, nil)
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+		}
+		if json.Unmarshal(message.Params, &params) == nil {
+			s.documents[params.TextDocument.URI] = params.TextDocument.Text
+			s.publishDiagnostics(params.TextDocument.URI)
+		}
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument   lspTextDocument `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if json.Unmarshal(message.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+			s.publishDiagnostics(params.TextDocument.URI)
+		}
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+		}
+		if json.Unmarshal(message.Params, &params) == nil {
+			delete(s.documents, params.TextDocument.URI)
+			s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+				"uri":         params.TextDocument.URI,
+				"diagnostics": []lspDiagnostic{},
+			})
+		}
+	case "textDocument/codeAction":
+		var params struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+			Range        lspRange        `json:"range"`
+		}
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			s.replyError(message.ID, lspInvalidParams, err.Error())
+			return
+		}
+		s.reply(message.ID, s.codeActions(params.TextDocument.URI, params.Range))
+	default:
+		// The unknown notifications are ignored, the unknown requests are answered with an error
+		if message.ID != nil {
+			s.replyError(message.ID, lspMethodNotFound, "unknown method "+message.Method)
+		}
+	}
+}
+
+// lspDocument is a parsed document with the source positions of his elements
+type lspDocument struct {
+	// text is the document text, the source is the text converted to html by the adapter of the document
+	text      string
+	source    *tableparser.SourceMap
+	root      *html.Node
+	tables    []tableparser.Result
+	sourceErr error
+}
+
+// parseDocument parse the tables of the document text, the fix options are applied on the document.
+// The markdown and template documents are converted to html by their adapter
+func (s *lspServer) parseDocument(uri string, text string, options tableparser.Options) lspDocument {
+	var document = lspDocument{text: text}
+	var source = adaptSource(uri, text, s.config)
+	document.source, document.root, document.sourceErr = tableparser.NewSourceMapReader(strings.NewReader(source))
+	if document.sourceErr != nil {
+		return document
+	}
+	document.tables, document.sourceErr = tableparser.ParseNode(document.root, options, s.config.Selector)
+	return document
+}
+
+// publishDiagnostics validate the document and send his diagnostics
+func (s *lspServer) publishDiagnostics(uri string) {
+	var text = s.documents[uri]
+	var document = s.parseDocument(uri, text, s.config.parserOptions())
+	var diagnostics = []lspDiagnostic{}
+
+	for _, table := range document.tables {
+		for _, diag := range table.Table.Diagnostics {
+			var elem = diag.Selection
+			if elem == nil || elem.Length() == 0 {
+				elem = table.Table.Selection
+			}
+			diagnostics = append(diagnostics, lspDiagnostic{
+				Range:    startTagRange(document.source, elem),
+				Severity: lspSeverity(diag.Severity),
+				Code:     diag.Rule.ID,
+				Source:   "tablevalidator",
+				Message:  diag.Message,
+			})
+		}
+	}
+
+	s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	})
+}
+
+// lspSeverity convert the severity, 1 is an error, 2 a warning and 3 an information
+func lspSeverity(severity tableparser.Severity) int {
+	switch severity {
+	case tableparser.SeverityError:
+		return 1
+	case tableparser.SeverityInfo:
+		return 3
+	}
+	return 2
+}
+
+// startTagRange return the range of the start tag of the element, or of the closest element with a tag
+func startTagRange(source *tableparser.SourceMap, elem *goquery.Selection) lspRange {
+	var start, end, ok = source.NearestStartTag(elem)
+	if ok == false {
+		return lspRange{}
+	}
+	return lspRange{Start: toLSPPosition(source, start), End: toLSPPosition(source, end)}
+}
+
+// toLSPPosition convert the position, the lsp lines start at 0 and the characters are in utf-16 code units
+func toLSPPosition(source *tableparser.SourceMap, position tableparser.Position) lspPosition {
+	var lineStart = position.Offset - position.Column + 1
+	var prefix = source.Source()[lineStart:position.Offset]
+	return lspPosition{
+		Line:      position.Line - 1,
+		Character: len(utf16.Encode([]rune(prefix))),
+	}
+}
+
+// rangeOverlap check if the ranges have a common position
+func rangeOverlap(a lspRange, b lspRange) bool {
+	return comparePosition(a.Start, b.End) <= 0 && comparePosition(b.Start, a.End) <= 0
+}
+
+func comparePosition(a lspPosition, b lspPosition) int {
+	if a.Line != b.Line {
+		return a.Line - b.Line
+	}
+	return a.Character - b.Character
+}
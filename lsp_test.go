@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// The key cells of the table can be linked to their row header cells with aria-describedby
+const lspTestHTML = `<table>
+<thead><tr><th>Key</th><th>Item</th><th>Price</th></tr></thead>
+<tbody>
+<tr><td id="k1">A</td><th>Apple</th><td>1</td></tr>
+<tr><td id="k2">P</td><th>Pear</th><td>2</td></tr>
+</tbody>
+</table>
+`
+
+// lspClient speak with a server over in-memory pipes, the messages are read with the framing of the server
+type lspClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *lspServer
+	nextID int
+	done   chan error
+}
+
+func newLSPClient(t *testing.T, config Config) *lspClient {
+	var serverIn, clientOut = io.Pipe()
+	var clientIn, serverOut = io.Pipe()
+	var server = &lspServer{
+		config:    config,
+		reader:    bufio.NewReader(serverIn),
+		writer:    serverOut,
+		documents: map[string]string{},
+	}
+	var client = &lspClient{
+		t:    t,
+		in:   clientOut,
+		out:  &lspServer{reader: bufio.NewReader(clientIn)},
+		done: make(chan error, 1),
+	}
+	go func() {
+		var err = server.run()
+		serverOut.Close()
+		client.done <- err
+	}()
+	return client
+}
+
+// send write a message with his Content-Length header
+func (c *lspClient) send(message map[string]interface{}) {
+	message["jsonrpc"] = "2.0"
+	content, err := json.Marshal(message)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(content), content); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *lspClient) notify(method string, params interface{}) {
+	c.send(map[string]interface{}{"method": method, "params": params})
+}
+
+// request send the request and decode the result of his response in result
+func (c *lspClient) request(method string, params interface{}, result interface{}) {
+	c.nextID++
+	c.send(map[string]interface{}{"id": c.nextID, "method": method, "params": params})
+	var message = c.read()
+	var id int
+	if message.ID == nil || json.Unmarshal(*message.ID, &id) != nil || id != c.nextID {
+		c.t.Fatalf("%s: response id = %v, want %d", method, message.ID, c.nextID)
+	}
+	if message.Error != nil {
+		c.t.Fatalf("%s: error %d %s", method, message.Error.Code, message.Error.Message)
+	}
+	decode(c.t, message.Result, result)
+}
+
+func (c *lspClient) read() lspMessage {
+	message, err := c.out.readMessage()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return message
+}
+
+// readDiagnostics read the next notification, it must publish the diagnostics of the document
+func (c *lspClient) readDiagnostics(uri string) []lspDiagnostic {
+	var message = c.read()
+	var params struct {
+		URI         string          `json:"uri"`
+		Diagnostics []lspDiagnostic `json:"diagnostics"`
+	}
+	if message.Method != "textDocument/publishDiagnostics" || message.ID != nil {
+		c.t.Fatalf("message = %+v, want the publishDiagnostics notification", message)
+	}
+	decode(c.t, message.Params, &params)
+	if params.URI != uri {
+		c.t.Fatalf("diagnostics uri = %q, want %q", params.URI, uri)
+	}
+	return params.Diagnostics
+}
+
+// decode convert the decoded json value in an other type
+func decode(t *testing.T, value interface{}, result interface{}) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(content, result); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLSPSession(t *testing.T) {
+	// The describedby fix of the configuration is also applied on the diagnostics parse,
+	// the code action must still find the changes
+	var config = defaultConfig()
+	config.Parser.FixDescribedBy = true
+	var client = newLSPClient(t, config)
+	var uri = "file:///project/table.html"
+	var document = map[string]interface{}{"uri": uri}
+
+	var initialize struct {
+		Capabilities struct {
+			TextDocumentSync   int  `json:"textDocumentSync"`
+			CodeActionProvider bool `json:"codeActionProvider"`
+		} `json:"capabilities"`
+	}
+	client.request("initialize", map[string]interface{}{}, &initialize)
+	if initialize.Capabilities.TextDocumentSync != 1 || initialize.Capabilities.CodeActionProvider == false {
+		t.Errorf("capabilities = %+v, want the full synchronization and the code actions", initialize.Capabilities)
+	}
+	client.notify("initialized", map[string]interface{}{})
+
+	// The table have a tfoot row wider than the table (code 37), the diagnostic is on the row
+	client.notify("textDocument/didOpen", map[string]interface{}{"textDocument": map[string]interface{}{
+		"uri": uri, "languageId": "html", "version": 1,
+		"text": strings.Replace(lspTestHTML, "</tbody>", "</tbody>\n<tfoot><tr><td>1</td><td>2</td><td>3</td><td>4</td></tr></tfoot>", 1),
+	}})
+	var diagnostics = client.readDiagnostics(uri)
+	if len(diagnostics) != 1 || diagnostics[0].Code != "tfoot-row-width" || diagnostics[0].Range.Start != (lspPosition{Line: 6, Character: 7}) {
+		t.Fatalf("didOpen diagnostics = %+v, want tfoot-row-width at 6:7", diagnostics)
+	}
+
+	// The whole text is replaced by the last change
+	client.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]string{{"text": "<table>"}, {"text": lspTestHTML}},
+	})
+	if diagnostics = client.readDiagnostics(uri); len(diagnostics) != 0 {
+		t.Fatalf("didChange diagnostics = %+v, want none", diagnostics)
+	}
+
+	// The describedby fix link each row header cell with his key cell
+	var actions = []lspCodeAction{}
+	client.request("textDocument/codeAction", map[string]interface{}{
+		"textDocument": document,
+		"range":        lspRange{Start: lspPosition{Line: 3, Character: 0}, End: lspPosition{Line: 3, Character: 0}},
+		"context":      map[string]interface{}{"diagnostics": []interface{}{}},
+	}, &actions)
+	var edits []lspTextEdit
+	for _, action := range actions {
+		if action.Title == "Link the description and key cells with aria-describedby" && action.Kind == "quickfix" {
+			edits = action.Edit.Changes[uri]
+		}
+	}
+	var want = []lspTextEdit{
+		{Range: lspRange{Start: lspPosition{Line: 3, Character: 22}, End: lspPosition{Line: 3, Character: 26}}, NewText: `<th aria-describedby="k1">`},
+		{Range: lspRange{Start: lspPosition{Line: 4, Character: 22}, End: lspPosition{Line: 4, Character: 26}}, NewText: `<th aria-describedby="k2">`},
+	}
+	if fmt.Sprint(edits) != fmt.Sprint(want) {
+		t.Errorf("describedby edits = %+v, want %+v\nactions = %+v", edits, want, actions)
+	}
+
+	// A range outside of the tables have no action
+	client.request("textDocument/codeAction", map[string]interface{}{
+		"textDocument": document,
+		"range":        lspRange{Start: lspPosition{Line: 7, Character: 0}, End: lspPosition{Line: 7, Character: 0}},
+	}, &actions)
+	if len(actions) != 0 {
+		t.Errorf("actions outside of the table = %+v, want none", actions)
+	}
+
+	// The closed document have no diagnostics, the unknown requests are answered with an error
+	client.notify("textDocument/didClose", map[string]interface{}{"textDocument": document})
+	if diagnostics = client.readDiagnostics(uri); len(diagnostics) != 0 {
+		t.Errorf("didClose diagnostics = %+v, want none", diagnostics)
+	}
+	client.send(map[string]interface{}{"id": 99, "method": "workspace/unknown"})
+	if message := client.read(); message.Error == nil || message.Error.Code != lspMethodNotFound {
+		t.Errorf("unknown request response = %+v, want the method not found error", message)
+	}
+
+	client.request("shutdown", nil, new(interface{}))
+	client.notify("exit", nil)
+	if err := <-client.done; err != nil {
+		t.Errorf("run = %v", err)
+	}
+}
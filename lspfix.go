@@ -0,0 +1,219 @@
+package main
+
+import (
+	"html"
+	"sort"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+// Code of the rule fixed by moving the header rows in the thead, see tableparser.RuleByCode
+const headerRowInTbodyCode = 21
+
+// codeActions return the fixes of the tables in the range
+func (s *lspServer) codeActions(uri string, rng lspRange) []lspCodeAction {
+	var actions = []lspCodeAction{}
+	var text, exists = s.documents[uri]
+	if exists == false {
+		return actions
+	}
+
+	// The original elements are parsed without the fix of the configuration, else the fix would not change them
+	var options = s.config.parserOptions()
+	options.FixDescribedBy = false
+	var document = s.parseDocument(uri, text, options)
+	if document.sourceErr != nil {
+		return actions
+	}
+
+	for i, table := range document.tables {
+		if rangeOverlap(elementRange(document.source, table.Table.Selection), rng) == false {
+			continue
+		}
+
+		// The fixes are applied on an other parse of the same text, then compared with the original elements
+		if len(table.Table.DescriptionCells) > 0 || len(table.Table.KeyCells) > 0 {
+			var fixOptions = options
+			fixOptions.FixDescribedBy = true
+			var fixed = s.parseDocument(uri, text, fixOptions)
+			if i < len(fixed.tables) {
+				var edits = startTagEdits(document, table.Table.Selection, fixed.tables[i].Table.Selection)
+				actions = appendCodeAction(actions, uri, "Link the description and key cells with aria-describedby", edits)
+			}
+		}
+
+		var scoped = s.parseDocument(uri, text, options)
+		if i < len(scoped.tables) {
+			addScope(scoped.tables[i].Table)
+			var edits = startTagEdits(document, table.Table.Selection, scoped.tables[i].Table.Selection)
+			actions = appendCodeAction(actions, uri, "Add the scope attribute to the header cells", edits)
+		}
+
+		for _, diag := range table.Table.Diagnostics {
+			if diag.Rule.Code == headerRowInTbodyCode {
+				var edits = moveHeaderRows(document, table.Table.Selection, diag.Selection)
+				actions = appendCodeAction(actions, uri, "Move the header rows in the thead", edits)
+			}
+		}
+	}
+	return actions
+}
+
+func appendCodeAction(actions []lspCodeAction, uri string, title string, edits []lspTextEdit) []lspCodeAction {
+	if len(edits) == 0 {
+		return actions
+	}
+	var action = lspCodeAction{Title: title, Kind: "quickfix"}
+	action.Edit.Changes = map[string][]lspTextEdit{uri: edits}
+	return append(actions, action)
+}
+
+// elementRange return the range of the element from his start tag to his end tag, or his start tag
+func elementRange(source *tableparser.SourceMap, elem *goquery.Selection) lspRange {
+	if start, end, ok := source.Element(elem); ok == true {
+		return lspRange{Start: toLSPPosition(source, start), End: toLSPPosition(source, end)}
+	}
+	return startTagRange(source, elem)
+}
+
+// startTagEdits replace the start tags of the elements that have different attributes in the fixed table,
+// both tables are parsed from the same source so their elements are in the same order
+func startTagEdits(document lspDocument, original *goquery.Selection, fixed *goquery.Selection) []lspTextEdit {
+	var source = document.source
+	var edits = []lspTextEdit{}
+	var originalElems = original.AddSelection(original.Find("*"))
+	var fixedElems = fixed.AddSelection(fixed.Find("*"))
+	if originalElems.Length() != fixedElems.Length() {
+		return edits
+	}
+
+	originalElems.Each(func(index int, elem *goquery.Selection) {
+		var fixedElem = fixedElems.Eq(index)
+		if renderStartTag(elem) == renderStartTag(fixedElem) {
+			return
+		}
+		var start, end, ok = source.StartTag(elem)
+		if ok == false {
+			// An implied element, like a tbody without tag, can not be changed
+			return
+		}
+		if document.text[start.Offset:end.Offset] != source.Source()[start.Offset:end.Offset] {
+			// The template actions of the tag would be lost
+			return
+		}
+		edits = append(edits, lspTextEdit{
+			Range:   lspRange{Start: toLSPPosition(source, start), End: toLSPPosition(source, end)},
+			NewText: renderStartTag(fixedElem),
+		})
+	})
+	return edits
+}
+
+// renderStartTag write the start tag of the element with his attributes
+func renderStartTag(elem *goquery.Selection) string {
+	var node = elem.Nodes[0]
+	var tag = "<" + node.Data
+	for _, attr := range node.Attr {
+		tag += " " + attr.Key + "=\"" + html.EscapeString(attr.Val) + "\""
+	}
+	return tag + ">"
+}
+
+// addScope set the scope attribute of the header cells without scope: col or row for the header cells,
+// colgroup or rowgroup for the group header cells
+func addScope(table *tableparser.Table) {
+	var grid = table.Grid()
+	for y, line := range grid {
+		// A row with data cells is a data row, his header cells are row headers
+		var dataRow = false
+		for _, slot := range line {
+			if slot.Type() == tableparser.TypeData || slot.Type() == tableparser.TypeSummary {
+				dataRow = true
+			}
+		}
+
+		for x, slot := range line {
+			var elem = slot.Selection()
+			if elem == nil || slot.RowPos() != y+1 || slot.ColPos() != x+1 || goquery.NodeName(elem) != "th" {
+				continue
+			}
+			if _, exists := elem.Attr("scope"); exists == true {
+				continue
+			}
+
+			var inThead = elem.ParentsFiltered("thead").Length() > 0
+			switch slot.Type() {
+			case tableparser.TypeHeader:
+				if dataRow && inThead == false {
+					elem.SetAttr("scope", "row")
+				} else {
+					elem.SetAttr("scope", "col")
+				}
+			case tableparser.TypeGroupHeader:
+				if inThead {
+					elem.SetAttr("scope", "colgroup")
+				} else {
+					elem.SetAttr("scope", "rowgroup")
+				}
+			}
+		}
+	}
+}
+
+// moveHeaderRows move the rows of the first tbody, up to the header row of the diagnostic, in the thead.
+// The thead is created when the table does not have one
+func moveHeaderRows(document lspDocument, table *goquery.Selection, row *goquery.Selection) []lspTextEdit {
+	var source = document.source
+	if row == nil || row.Length() == 0 || goquery.NodeName(row) != "tr" {
+		return nil
+	}
+	var tbody = row.Parent()
+	if goquery.NodeName(tbody) != "tbody" || tbody.IsSelection(table.ChildrenFiltered("tbody").First()) == false {
+		return nil
+	}
+
+	var rows = tbody.ChildrenFiltered("tr")
+	var last = rows.IndexOfSelection(row)
+	var start, _, firstOk = source.Element(rows.First())
+	var _, end, lastOk = source.Element(row)
+	if last < 0 || firstOk == false || lastOk == false {
+		return nil
+	}
+	// The rows are copied from the document text, with their template actions
+	var rowsText = document.text[start.Offset:end.Offset]
+
+	var deleteRange = lspRange{Start: toLSPPosition(source, start), End: toLSPPosition(source, end)}
+	var thead = table.ChildrenFiltered("thead")
+	if thead.Length() > 0 {
+		var endTagStart, _, ok = source.EndTag(thead)
+		if ok == false {
+			return nil
+		}
+		var insert = toLSPPosition(source, endTagStart)
+		return []lspTextEdit{
+			{Range: lspRange{Start: insert, End: insert}, NewText: rowsText},
+			{Range: deleteRange, NewText: ""},
+		}
+	}
+
+	var tbodyStart, _, explicitTbody = source.StartTag(tbody)
+	if explicitTbody == false {
+		// The rows are directly in the table, the tbody is implied after the thead
+		return []lspTextEdit{{Range: deleteRange, NewText: "<thead>" + rowsText + "</thead>"}}
+	}
+	var insert = toLSPPosition(source, tbodyStart)
+	return sortEdits([]lspTextEdit{
+		{Range: lspRange{Start: insert, End: insert}, NewText: "<thead>" + rowsText + "</thead>\n"},
+		{Range: deleteRange, NewText: ""},
+	})
+}
+
+// sortEdits keep the edits in the document order
+func sortEdits(edits []lspTextEdit) []lspTextEdit {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return comparePosition(edits[i].Range.Start, edits[j].Range.Start) < 0
+	})
+	return edits
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+type jsonDiagnostic struct {
+	Code     int    `json:"code"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// Key and Args are the message key and the message arguments, to translate the message
+	Key  string            `json:"key"`
+	Args map[string]string `json:"args,omitempty"`
+	// Criteria and Techniques are the WCAG references of the rule
+	Criteria   []string `json:"criteria"`
+	Techniques []string `json:"techniques"`
+	// Line is the line of the element in the file, when it is known
+	Line int `json:"line,omitempty"`
+	// Suppressed is set when the problem is suppressed in the html
+	Suppressed bool `json:"suppressed,omitempty"`
+}
+
+type jsonTable struct {
+	Index       int              `json:"index"`
+	Fingerprint string           `json:"fingerprint"`
+	Caption     *jsonCaption     `json:"caption,omitempty"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+}
+
+// jsonCaption is the caption of a table, see tableparser.Caption
+type jsonCaption struct {
+	Text                  string   `json:"text,omitempty"`
+	Descriptions          []string `json:"descriptions,omitempty"`
+	Summary               string   `json:"summary,omitempty"`
+	AccessibleName        string   `json:"accessibleName,omitempty"`
+	NameSource            string   `json:"nameSource,omitempty"`
+	AccessibleDescription string   `json:"accessibleDescription,omitempty"`
+}
+
+type jsonFile struct {
+	Path   string      `json:"path"`
+	Error  string      `json:"error,omitempty"`
+	Tables []jsonTable `json:"tables"`
+}
+
+// reportOptions are the options of the report
+type reportOptions struct {
+	// ShowSuppressed write the suppressed problems
+	ShowSuppressed bool
+	// GroupBy group the text report by "file" or by WCAG "criterion"
+	GroupBy string
+	// Grid draw the table grid under each problem of the text report
+	Grid bool
+	// Color write the text report with colors
+	Color bool
+}
+
+// writeReport write the results in the output format
+func writeReport(w io.Writer, format string, results []FileResult, opts reportOptions) error {
+	switch opts.GroupBy {
+	case "", "file":
+	case "criterion":
+		if format == "text" || format == "" {
+			writeCriterionReport(w, results, opts.ShowSuppressed)
+			return nil
+		}
+	default:
+		return errors.New("unknown report grouping \"" + opts.GroupBy + "\"")
+	}
+
+	switch format {
+	case "text", "":
+		writeTextReport(w, results, opts)
+		return nil
+	case "json":
+		return writeJSONReport(w, results, opts.ShowSuppressed)
+	case "html":
+		return writeHTMLReport(w, results, opts.ShowSuppressed)
+	}
+	return errors.New("unknown output format \"" + format + "\"")
+}
+
+// writeTextReport write one line per diagnostic, followed by the table grid when it is set in the options
+func writeTextReport(w io.Writer, results []FileResult, opts reportOptions) {
+	if writeTextResults(w, results, opts) {
+		fmt.Fprintln(w, gridLegend)
+	}
+}
+
+// writeTextResults write the diagnostics of the results, it return true when a grid is drawn
+func writeTextResults(w io.Writer, results []FileResult, opts reportOptions) bool {
+	var hasGrid = false
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(w, "%s: %v\n", result.Path, result.Err)
+			continue
+		}
+		for _, table := range result.Tables {
+			for _, diag := range table.Diagnostics {
+				fmt.Fprintf(w, "%s: table %d [%s]: %v\n", result.location(diag), table.Index, table.Fingerprint, diag)
+				if opts.Grid {
+					writeGrid(w, table.Table.Grid(), diag, opts.Color)
+					hasGrid = true
+				}
+			}
+			if opts.ShowSuppressed {
+				for _, diag := range table.Suppressed {
+					fmt.Fprintf(w, "%s: table %d [%s]: %v (suppressed)\n", result.location(diag), table.Index, table.Fingerprint, diag)
+				}
+			}
+		}
+	}
+	return hasGrid
+}
+
+func writeJSONReport(w io.Writer, results []FileResult, showSuppressed bool) error {
+	var files = []jsonFile{}
+	for _, result := range results {
+		var file = jsonFile{
+			Path:   result.Path,
+			Tables: []jsonTable{},
+		}
+		if result.Err != nil {
+			file.Error = result.Err.Error()
+		}
+		for _, table := range result.Tables {
+			var tbl = jsonTable{
+				Index:       table.Index,
+				Fingerprint: table.Fingerprint,
+				Caption:     newJSONCaption(table.Caption),
+				Diagnostics: []jsonDiagnostic{},
+			}
+			for _, diag := range table.Diagnostics {
+				var jsonDiag = newJSONDiagnostic(diag, false)
+				jsonDiag.Line = result.line(diag)
+				tbl.Diagnostics = append(tbl.Diagnostics, jsonDiag)
+			}
+			if showSuppressed {
+				for _, diag := range table.Suppressed {
+					var jsonDiag = newJSONDiagnostic(diag, true)
+					jsonDiag.Line = result.line(diag)
+					tbl.Diagnostics = append(tbl.Diagnostics, jsonDiag)
+				}
+			}
+			file.Tables = append(file.Tables, tbl)
+		}
+		files = append(files, file)
+	}
+
+	var encoder = json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(files)
+}
+
+// newJSONCaption return the caption of the json report, nil when the table have no caption and no name
+func newJSONCaption(caption tableparser.Caption) *jsonCaption {
+	if len(caption.Text) == 0 && len(caption.Descriptions) == 0 && len(caption.Summary) == 0 && len(caption.AccessibleName) == 0 {
+		return nil
+	}
+	return &jsonCaption{
+		Text:                  caption.Text,
+		Descriptions:          caption.Descriptions,
+		Summary:               caption.Summary,
+		AccessibleName:        caption.AccessibleName,
+		NameSource:            caption.NameSource,
+		AccessibleDescription: caption.AccessibleDescription,
+	}
+}
+
+func newJSONDiagnostic(diag *tableparser.Diagnostic, suppressed bool) jsonDiagnostic {
+	return jsonDiagnostic{
+		Code:       diag.Rule.Code,
+		Rule:       diag.Rule.ID,
+		Severity:   diag.Severity.String(),
+		Message:    diag.Message,
+		Key:        diag.Key,
+		Args:       diag.Args,
+		Criteria:   append([]string{}, diag.Rule.Criteria...),
+		Techniques: append([]string{}, diag.Rule.Techniques...),
+		Suppressed: suppressed,
+	}
+}
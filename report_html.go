@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+type htmlReport struct {
+	Files []htmlFile
+	Types []htmlType
+}
+
+type htmlType struct {
+	Type int
+	Name string
+}
+
+type htmlFile struct {
+	ID     string
+	Path   string
+	Error  string
+	Tables []htmlTable
+	// Problems is the number of reported problems in the file
+	Problems int
+}
+
+type htmlTable struct {
+	ID          string
+	Index       int
+	Fingerprint string
+	Caption     string
+	Rows        [][]htmlCell
+	Diagnostics []htmlDiagnostic
+}
+
+type htmlCell struct {
+	ID       string
+	Tag      string
+	Type     int
+	TypeName string
+	Colspan  int
+	Rowspan  int
+	Text     string
+	// Headers are the ids of the header cells, HeaderText is their text
+	Headers    string
+	HeaderText string
+	// Problems are the numbers of the diagnostics of the cell
+	Problems []int
+}
+
+type htmlDiagnostic struct {
+	Number     int
+	Severity   string
+	Code       int
+	Rule       string
+	Message    string
+	CellID     string
+	Suppressed bool
+}
+
+// writeHTMLReport write a self-contained html page, each table is drawn with his cells colored by type
+func writeHTMLReport(w io.Writer, results []FileResult, showSuppressed bool) error {
+	var report = htmlReport{
+		Files: []htmlFile{},
+		Types: []htmlType{},
+	}
+	for etype := tableparser.TypeHeader; etype <= tableparser.TypeGroupHeader; etype++ {
+		report.Types = append(report.Types, htmlType{Type: int(etype), Name: etype.String()})
+	}
+
+	for fileIndex, result := range results {
+		var file = htmlFile{
+			ID:     fmt.Sprintf("f%d", fileIndex+1),
+			Path:   result.Path,
+			Tables: []htmlTable{},
+		}
+		if result.Err != nil {
+			file.Error = result.Err.Error()
+		}
+		for _, table := range result.Tables {
+			file.Tables = append(file.Tables, newHTMLTable(file.ID, table, showSuppressed))
+			file.Problems += len(table.Diagnostics)
+		}
+		report.Files = append(report.Files, file)
+	}
+
+	return htmlReportTemplate.Execute(w, report)
+}
+
+// newHTMLTable convert the grid of the table, the cells are written at their first slot with their spans
+func newHTMLTable(fileID string, result TableResult, showSuppressed bool) htmlTable {
+	var table = htmlTable{
+		ID:          fmt.Sprintf("%s-t%d", fileID, result.Index),
+		Index:       result.Index,
+		Fingerprint: result.Fingerprint,
+		Rows:        [][]htmlCell{},
+		Diagnostics: []htmlDiagnostic{},
+	}
+	if result.Table == nil {
+		return table
+	}
+	table.Caption = strings.Join(strings.Fields(result.Table.Selection.ChildrenFiltered("caption").Text()), " ")
+
+	var diagnostics = append(tableparser.Diagnostics{}, result.Diagnostics...)
+	if showSuppressed {
+		diagnostics = append(diagnostics, result.Suppressed...)
+	}
+	for i, diag := range diagnostics {
+		table.Diagnostics = append(table.Diagnostics, htmlDiagnostic{
+			Number:     i + 1,
+			Severity:   diag.Severity.String(),
+			Code:       diag.Rule.Code,
+			Rule:       diag.Rule.ID,
+			Message:    diag.Message,
+			Suppressed: i >= len(result.Diagnostics),
+		})
+	}
+
+	var cellID = func(cell tableparser.Cell) string {
+		return fmt.Sprintf("%s-r%dc%d", table.ID, cell.RowPos(), cell.ColPos())
+	}
+
+	var grid = result.Table.Grid()
+	for y, line := range grid {
+		var row = []htmlCell{}
+		for x, slot := range line {
+			if slot.Selection() == nil {
+				row = append(row, htmlCell{Tag: "td", Colspan: 1, Rowspan: 1})
+				continue
+			}
+			if slot.RowPos() != y+1 || slot.ColPos() != x+1 {
+				// Spanned slot, the cell is already written
+				continue
+			}
+
+			var cell = htmlCell{
+				ID:       cellID(slot),
+				Tag:      goquery.NodeName(slot.Selection()),
+				Type:     int(slot.Type()),
+				TypeName: slot.Type().String(),
+				Colspan:  slot.Width(),
+				Rowspan:  slot.Height(),
+				Text:     slot.Text(),
+				Problems: []int{},
+			}
+			var ids, texts = []string{}, []string{}
+			for _, header := range result.Table.Headers(slot) {
+				ids = append(ids, cellID(header))
+				texts = append(texts, header.Text())
+			}
+			cell.Headers = strings.Join(ids, " ")
+			cell.HeaderText = strings.Join(texts, ", ")
+
+			for i, diag := range diagnostics {
+				if isProblemCell(slot, diag) {
+					cell.Problems = append(cell.Problems, i+1)
+					if len(table.Diagnostics[i].CellID) == 0 {
+						table.Diagnostics[i].CellID = cell.ID
+					}
+				}
+			}
+			row = append(row, cell)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Table validation report</title>
+<style>
+body { font-family: sans-serif; margin: 0; display: flex; }
+nav { width: 18em; padding: 1em; border-right: 1px solid #ccc; height: 100vh; overflow: auto; position: sticky; top: 0; }
+nav ul { padding-left: 1.2em; }
+main { padding: 1em 2em; flex: 1; }
+table.grid { border-collapse: collapse; margin: 1em 0; }
+table.grid th, table.grid td { border: 1px solid #999; padding: .3em .6em; min-width: 3em; }
+.etype-1 { background: #cfe8fc; }
+.etype-2 { background: #fff; }
+.etype-3 { background: #f3d9fa; }
+.etype-4 { background: #fff3bf; }
+.etype-5 { background: #d0ebff; font-style: italic; }
+.etype-6 { background: #e9ecef; }
+.etype-7 { background: #d3f9d8; }
+.etype-0 { background: repeating-linear-gradient(45deg, #fff, #fff 4px, #eee 4px, #eee 8px); }
+.problem { outline: 3px solid #e03131; outline-offset: -3px; }
+.assoc { box-shadow: inset 0 0 0 3px #1c7ed6; }
+.badge { background: #e03131; color: #fff; border-radius: .8em; padding: 0 .4em; font-size: .75em; margin-left: .3em; }
+.legend span { display: inline-block; padding: .2em .6em; margin-right: .3em; border: 1px solid #999; }
+.error { color: #e03131; }
+.suppressed { color: #868e96; }
+</style>
+</head>
+<body>
+<nav>
+<h2>Files</h2>
+<ul>
+{{range .Files}}<li><a href="#{{.ID}}">{{.Path}}</a> ({{.Problems}})
+<ul>{{range .Tables}}<li><a href="#{{.ID}}">table {{.Index}}</a>{{if .Caption}} {{.Caption}}{{end}}</li>{{end}}</ul>
+</li>
+{{end}}</ul>
+</nav>
+<main>
+<h1>Table validation report</h1>
+<p class="legend">{{range .Types}}<span class="etype-{{.Type}}">{{.Name}}</span>{{end}}<span class="etype-0">not parsed</span></p>
+{{range .Files}}
+<section id="{{.ID}}">
+<h2>{{.Path}}</h2>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{range .Tables}}
+<section id="{{.ID}}">
+<h3>Table {{.Index}}{{if .Caption}}: {{.Caption}}{{end}} <small>[{{.Fingerprint}}]</small></h3>
+<table class="grid">
+{{range .Rows}}<tr>{{range .}}{{if eq .Tag "th"}}<th{{else}}<td{{end}}{{if .ID}} id="{{.ID}}"{{end}} class="etype-{{.Type}}{{if .Problems}} problem{{end}}" colspan="{{.Colspan}}" rowspan="{{.Rowspan}}"{{if .Headers}} data-headers="{{.Headers}}"{{end}} title="{{.TypeName}}{{if .HeaderText}} - headers: {{.HeaderText}}{{end}}">{{.Text}}{{range .Problems}}<span class="badge">{{.}}</span>{{end}}{{if eq .Tag "th"}}</th>{{else}}</td>{{end}}{{end}}</tr>
+{{end}}</table>
+{{if .Diagnostics}}<ol>
+{{range .Diagnostics}}<li{{if .Suppressed}} class="suppressed"{{end}}>{{.Severity}} {{.Code}} ({{.Rule}}): {{.Message}}{{if .CellID}} <a href="#{{.CellID}}">cell</a>{{end}}{{if .Suppressed}} (suppressed){{end}}</li>
+{{end}}</ol>{{else}}<p>No problem found.</p>{{end}}
+</section>
+{{end}}
+</section>
+{{end}}
+</main>
+<script>
+document.querySelectorAll("[data-headers]").forEach(function (cell) {
+	var headers = cell.getAttribute("data-headers").split(" ").map(function (id) {
+		return document.getElementById(id);
+	}).filter(Boolean);
+	cell.addEventListener("mouseenter", function () {
+		headers.forEach(function (header) { header.classList.add("assoc"); });
+	});
+	cell.addEventListener("mouseleave", function () {
+		headers.forEach(function (header) { header.classList.remove("assoc"); });
+	});
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the reports")
+
+// TestJSONReport compare the json report of each html file of the testdata directory with his golden file
+func TestJSONReport(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no test file")
+	}
+
+	for _, path := range files {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			var result = validateFile(context.Background(), path, defaultConfig())
+			var output = bytes.Buffer{}
+			if err := writeJSONReport(&output, []FileResult{result}, false); err != nil {
+				t.Fatal(err)
+			}
+
+			var golden = strings.TrimSuffix(path, ".html") + ".golden.json"
+			if *update {
+				if err := ioutil.WriteFile(golden, output.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(output.Bytes(), expected) == false {
+				t.Errorf("json report of %s:\n%s\nwant (go test -update to record it):\n%s", path, output.String(), expected)
+			}
+		})
+	}
+}
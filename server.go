@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+// Default size limit of the validated html
+const defaultMaxRequestSize = 10 << 20
+
+// Timeouts of the http server, the slow clients can not keep the connections open
+const (
+	serverReadHeaderTimeout = 10 * time.Second
+	serverReadTimeout       = time.Minute
+	serverWriteTimeout      = 2 * time.Minute
+)
+
+// validateRequest is the json body of the validate endpoint
+type validateRequest struct {
+	HTML string `json:"html"`
+	// Selector is the css selector of the validated tables
+	Selector string `json:"selector"`
+	// Fix link the header cells with their description and key cells, the fixed html is returned
+	Fix bool `json:"fix"`
+	// Lang is the language of the messages
+	Lang string `json:"lang"`
+}
+
+// validateResponse is the json response of the validate endpoint
+type validateResponse struct {
+	Tables []jsonTable `json:"tables"`
+	// HTML is the fixed html, when it is requested
+	HTML string `json:"html,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// server is the http validation service, the table parser lock his state
+// so the tables are parsed one request at a time
+type server struct {
+	config         Config
+	maxRequestSize int64
+}
+
+// newServer return the handler of the validation service:
+// POST /validate with raw html or a json body, GET /health
+func newServer(config Config, maxRequestSize int64) http.Handler {
+	var s = &server{
+		config:         config,
+		maxRequestSize: maxRequestSize,
+	}
+
+	var mux = http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/validate", s.handleValidate)
+	return mux
+}
+
+// runServe is the serve subcommand
+func runServe(args []string) {
+	var flags = flag.NewFlagSet("serve", flag.ExitOnError)
+	var addr = flags.String("addr", ":8080", "address of the http server")
+	var maxSize = flags.Int64("max-size", defaultMaxRequestSize, "maximum size of the validated html, in bytes")
+	var configPath = flags.String("config", "", "configuration file, .tablevalidator.yaml or .tablevalidator.json in the working directory by default")
+	flags.Parse(args)
+
+	var config = defaultConfig()
+	if len(*configPath) == 0 {
+		*configPath = findConfig(".")
+	}
+	if len(*configPath) != 0 {
+		var err error
+		config, err = loadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if err := config.loadMessages(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var httpServer = &http.Server{
+		Addr:              *addr,
+		Handler:           newServer(config, *maxSize),
+		ReadHeaderTimeout: serverReadHeaderTimeout,
+		ReadTimeout:       serverReadTimeout,
+		WriteTimeout:      serverWriteTimeout,
+	}
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", *addr)
+	if err := httpServer.ListenAndServe(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeJSONError(w, http.StatusMethodNotAllowed, "use the GET method")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleValidate validate the tables of the html, the body is the raw html or a json validateRequest.
+// With the raw html, the selector, fix and lang options are query parameters
+func (s *server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, "use the POST method")
+		return
+	}
+
+	var request, err = s.readRequest(w, r)
+	if err != nil {
+		var status = http.StatusBadRequest
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) == true {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	if len(request.Selector) != 0 {
+		if _, err := cascadia.Compile(request.Selector); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid selector: "+err.Error())
+			return
+		}
+	}
+	if len(request.Lang) != 0 && tableparser.HasLang(request.Lang) == false {
+		writeJSONError(w, http.StatusBadRequest, "unknown language \""+request.Lang+"\"")
+		return
+	}
+
+	response, err := s.validate(r, request)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// readRequest read the raw html or the json body, up to the size limit
+func (s *server) readRequest(w http.ResponseWriter, r *http.Request) (validateRequest, error) {
+	var request = validateRequest{}
+	var body = http.MaxBytesReader(w, r.Body, s.maxRequestSize)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(body).Decode(&request); err != nil {
+			return request, err
+		}
+		if len(strings.TrimSpace(request.HTML)) == 0 {
+			return request, errors.New("the html is empty")
+		}
+		return request, nil
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return request, err
+	}
+	var query = r.URL.Query()
+	request.HTML = string(content)
+	request.Selector = query.Get("selector")
+	request.Fix = query.Get("fix") == "true" || query.Get("fix") == "1"
+	request.Lang = query.Get("lang")
+	if len(strings.TrimSpace(request.HTML)) == 0 {
+		return request, errors.New("the html is empty")
+	}
+	return request, nil
+}
+
+// validate parse the tables of the request
+func (s *server) validate(r *http.Request, request validateRequest) (validateResponse, error) {
+	var response = validateResponse{Tables: []jsonTable{}}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(request.HTML))
+	if err != nil {
+		return response, err
+	}
+
+	var options = s.config.parserOptions()
+	options.FixDescribedBy = options.FixDescribedBy || request.Fix
+	if len(request.Lang) != 0 {
+		options.Lang = request.Lang
+	}
+	var selector = request.Selector
+	if len(selector) == 0 {
+		selector = s.config.Selector
+	}
+
+	results, err := tableparser.ParseSelectionContext(r.Context(), doc.Selection, options, selector)
+	if err != nil {
+		return response, err
+	}
+
+	for _, result := range results {
+		var table = jsonTable{
+			Index:       result.Index,
+			Fingerprint: result.Table.Fingerprint,
+			Caption:     newJSONCaption(result.Table.Caption),
+			Diagnostics: []jsonDiagnostic{},
+		}
+		for _, diag := range result.Table.Diagnostics {
+			table.Diagnostics = append(table.Diagnostics, newJSONDiagnostic(diag, false))
+		}
+		for _, diag := range result.Table.Suppressed {
+			table.Diagnostics = append(table.Diagnostics, newJSONDiagnostic(diag, true))
+		}
+		response.Tables = append(response.Tables, table)
+	}
+
+	if request.Fix {
+		response.HTML, err = goquery.OuterHtml(doc.Selection)
+	}
+	return response, err
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+
+	// The fixed html is easier to read without the escaped characters
+	var encoder = json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
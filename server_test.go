@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// The second table have a tfoot row wider than the table (code 37)
+const serverTestHTML = `<table><tr><th>A</th><td>1</td></tr></table>
+<table><thead><tr><th>A</th><th>B</th></tr></thead><tbody><tr><td>1</td><td>2</td></tr></tbody>
+<tfoot><tr><td>3</td><td>4</td><td>5</td></tr></tfoot></table>`
+
+func TestServer(t *testing.T) {
+	var tests = []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+		tables      int
+		codes       []int
+		err         string
+	}{
+		{name: "health", method: http.MethodGet, path: "/health", status: http.StatusOK},
+		{name: "health method", method: http.MethodPost, path: "/health", status: http.StatusMethodNotAllowed, err: "GET"},
+		{name: "validate method", method: http.MethodGet, path: "/validate", status: http.StatusMethodNotAllowed, err: "POST"},
+		{name: "raw html", method: http.MethodPost, path: "/validate", contentType: "text/html", body: serverTestHTML,
+			status: http.StatusOK, tables: 2, codes: []int{37}},
+		{name: "raw html selector", method: http.MethodPost, path: "/validate?selector=table:first-of-type", contentType: "text/html",
+			body: serverTestHTML, status: http.StatusOK, tables: 1, codes: []int{}},
+		{name: "json", method: http.MethodPost, path: "/validate", contentType: "application/json",
+			body: jsonBody(t, validateRequest{HTML: serverTestHTML}), status: http.StatusOK, tables: 2, codes: []int{37}},
+		{name: "too large", method: http.MethodPost, path: "/validate", contentType: "text/html",
+			body: "<table>" + strings.Repeat("<tr><td>1</td></tr>", 100) + "</table>", status: http.StatusRequestEntityTooLarge},
+		{name: "too large json", method: http.MethodPost, path: "/validate", contentType: "application/json",
+			body: jsonBody(t, validateRequest{HTML: "<table>" + strings.Repeat("<tr><td>1</td></tr>", 100) + "</table>"}), status: http.StatusRequestEntityTooLarge},
+		{name: "bad json", method: http.MethodPost, path: "/validate", contentType: "application/json",
+			body: `{"html": `, status: http.StatusBadRequest},
+		{name: "empty json html", method: http.MethodPost, path: "/validate", contentType: "application/json",
+			body: `{"selector": "table"}`, status: http.StatusBadRequest, err: "the html is empty"},
+		{name: "empty raw html", method: http.MethodPost, path: "/validate", contentType: "text/html",
+			body: " ", status: http.StatusBadRequest, err: "the html is empty"},
+		{name: "invalid selector", method: http.MethodPost, path: "/validate?selector=table[", contentType: "text/html",
+			body: serverTestHTML, status: http.StatusBadRequest, err: "invalid selector"},
+		{name: "unknown language", method: http.MethodPost, path: "/validate?lang=xx", contentType: "text/html",
+			body: serverTestHTML, status: http.StatusBadRequest, err: "unknown language"},
+	}
+
+	var handler = newServer(defaultConfig(), 1024)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var request = httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			if len(test.contentType) != 0 {
+				request.Header.Set("Content-Type", test.contentType)
+			}
+			var recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, test.status, recorder.Body.String())
+			}
+			if test.status != http.StatusOK {
+				var response = errorResponse{}
+				if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+					t.Fatal(err)
+				}
+				if len(response.Error) == 0 || strings.Contains(response.Error, test.err) == false {
+					t.Errorf("error = %q, want %q", response.Error, test.err)
+				}
+				return
+			}
+			if test.path == "/health" {
+				return
+			}
+
+			var response = validateResponse{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Tables) != test.tables {
+				t.Fatalf("tables = %d, want %d", len(response.Tables), test.tables)
+			}
+			var codes = []int{}
+			for _, table := range response.Tables {
+				for _, diag := range table.Diagnostics {
+					codes = append(codes, diag.Code)
+				}
+			}
+			if reflect.DeepEqual(codes, test.codes) == false {
+				t.Errorf("codes = %v, want %v", codes, test.codes)
+			}
+		})
+	}
+}
+
+// The requests are validated concurrently, the parser lock his state (go test -race)
+func TestServerConcurrentRequests(t *testing.T) {
+	var handler = newServer(defaultConfig(), defaultMaxRequestSize)
+	var wait = sync.WaitGroup{}
+	var codes = make([]int, 8)
+	for i := range codes {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			var request = httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(serverTestHTML))
+			var recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			var response = validateResponse{}
+			if recorder.Code != http.StatusOK || json.Unmarshal(recorder.Body.Bytes(), &response) != nil || len(response.Tables) != 2 {
+				return
+			}
+			for _, diag := range response.Tables[1].Diagnostics {
+				codes[i] = diag.Code
+			}
+		}(i)
+	}
+	wait.Wait()
+
+	for i, code := range codes {
+		if code != 37 {
+			t.Errorf("request %d: tfoot width code = %d, want 37", i, code)
+		}
+	}
+}
+
+func jsonBody(t *testing.T, request validateRequest) string {
+	content, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+// streamFiles validate the files one table at a time, the text report is written as soon as a table is parsed.
+// The parsed tables are not kept in the results, only their diagnostics
+func streamFiles(ctx context.Context, w io.Writer, files []string, config Config, opts reportOptions, updateBaseline bool) ([]FileResult, error) {
+	if config.Format != "text" && config.Format != "json" {
+		return nil, errors.New("the stream mode only write the text and json formats")
+	}
+	if len(config.Selector) != 0 || opts.GroupBy == "criterion" {
+		return nil, errors.New("the stream mode can not be used with a selector or with the criterion grouping")
+	}
+
+	// The known problems are removed before they are written, a new baseline is recorded at the end
+	var filter *baselineFilter
+	var recordBaseline = false
+	if len(config.Baseline) != 0 {
+		if _, err := os.Stat(config.Baseline); os.IsNotExist(err) || updateBaseline {
+			recordBaseline = true
+		} else {
+			baseline, err := loadBaseline(config.Baseline)
+			if err != nil {
+				return nil, err
+			}
+			filter = baseline.newFilter()
+		}
+	}
+
+	var results = []FileResult{}
+	var known = 0
+	var hasGrid = false
+	for _, path := range files {
+		var result = FileResult{
+			Path:   path,
+			Tables: []TableResult{},
+		}
+
+		result.Err = streamFile(ctx, path, config, func(table tableparser.Result) error {
+			var tableResult = FileResult{
+				Path: path,
+				Tables: []TableResult{{
+					Index:       table.Index,
+					Fingerprint: table.Table.Fingerprint,
+					Caption:     table.Table.Caption,
+					Diagnostics: table.Table.Diagnostics,
+					Suppressed:  table.Table.Suppressed,
+					Table:       table.Table,
+				}},
+			}
+			if filter != nil {
+				known += filter.filter([]FileResult{tableResult})
+			}
+			if config.Format == "text" && recordBaseline == false {
+				hasGrid = writeTextResults(w, []FileResult{tableResult}, opts) || hasGrid
+			}
+
+			result.Tables = append(result.Tables, releaseTable(tableResult.Tables[0]))
+			return nil
+		})
+		if result.Err != nil && config.Format == "text" {
+			fmt.Fprintf(w, "%s: %v\n", result.Path, result.Err)
+		}
+		results = append(results, result)
+	}
+
+	if recordBaseline {
+		var baseline = newBaseline(results)
+		if err := baseline.save(config.Baseline); err != nil {
+			return results, err
+		}
+		known = baseline.filter(results)
+	}
+	writeKnownProblems(known, config.Baseline)
+
+	if hasGrid {
+		fmt.Fprintln(w, gridLegend)
+	}
+	if config.Format == "json" {
+		return results, writeJSONReport(w, results, opts.ShowSuppressed)
+	}
+	return results, nil
+}
+
+// streamFile validate the tables of the file with the html tokenizer. The markdown and template files
+// are read at once to be converted to html
+func streamFile(ctx context.Context, path string, config Config, fn func(tableparser.Result) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if adapter := fileAdapter(path, config); adapter != nil {
+		content, err := ioutil.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		reader = strings.NewReader(adapter(string(content)))
+	}
+	return tableparser.StreamReader(ctx, reader, config.parserOptions(), fn)
+}
+
+// releaseTable drop the parsed table and the elements of the diagnostics, so the table can be freed
+func releaseTable(table TableResult) TableResult {
+	table.Table = nil
+	table.Caption.Element = nil
+	for _, diag := range table.Diagnostics {
+		diag.Selection = nil
+	}
+	for _, diag := range table.Suppressed {
+		diag.Selection = nil
+	}
+	return table
+}
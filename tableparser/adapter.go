@@ -0,0 +1,262 @@
+package tableparser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Adapter convert a source file to html for the parser. The parts that are not html are replaced by spaces
+// and the line breaks are kept, so the positions in the html are the positions in the source file
+type Adapter func(source string) string
+
+// Start and end tags of a table, to find the end of a markdown html block that contain a table
+var tableTagRegexp = regexp.MustCompile(`(?i)<(/?)table[\s>/]`)
+
+// Keywords of the go template actions that do not write any content
+var goControlKeywords = []string{"if", "else", "end", "range", "with", "define", "block", "break", "continue"}
+
+// MarkdownAdapter keep the raw html blocks of a markdown file. An html block start with a tag at the start of a line
+// and end with a blank line, like in CommonMark, but a block with a table end after the table end tag
+// so the tables can have blank lines. The code blocks are removed
+func MarkdownAdapter(source string) string {
+	var output = []byte(source)
+	var inHTML = false
+	var tableDepth = 0
+	var fence = ""
+
+	var offset = 0
+	for offset < len(source) {
+		var end = strings.IndexByte(source[offset:], '\n')
+		if end < 0 {
+			end = len(source)
+		} else {
+			end = offset + end + 1
+		}
+		var line = source[offset:end]
+		var lineStart = offset
+		offset = end
+
+		var indent = 0
+		for indent < len(line) && line[indent] == ' ' {
+			indent++
+		}
+		var trimmed = strings.TrimSpace(line)
+
+		// Check for a fenced code block
+		if len(fence) != 0 {
+			if indent <= 3 && strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			blank(output, lineStart, end)
+			continue
+		}
+
+		if inHTML {
+			if len(trimmed) == 0 && tableDepth == 0 {
+				inHTML = false
+				continue
+			}
+			tableDepth = updateTableDepth(tableDepth, line)
+			continue
+		}
+
+		if indent <= 3 && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")) {
+			fence = trimmed[:3]
+			for len(fence) < len(trimmed) && trimmed[len(fence)] == fence[0] {
+				fence += fence[:1]
+			}
+			blank(output, lineStart, end)
+			continue
+		}
+
+		// Check for the start of an html block, the indented lines are a code block
+		if indent <= 3 && len(trimmed) > 1 && trimmed[0] == '<' && isTagStart(trimmed[1]) {
+			inHTML = true
+			tableDepth = updateTableDepth(tableDepth, line)
+			continue
+		}
+
+		blank(output, lineStart, end)
+	}
+	return string(output)
+}
+
+// updateTableDepth add the table start tags and remove the table end tags of the line
+func updateTableDepth(depth int, line string) int {
+	for _, match := range tableTagRegexp.FindAllStringSubmatch(line, -1) {
+		if len(match[1]) == 0 {
+			depth++
+		} else if depth > 0 {
+			depth--
+		}
+	}
+	return depth
+}
+
+// GoTemplateAdapter remove the comments and the control actions of a go html/template file, like {{if}} or {{end}},
+// and the actions inside the tags. The other actions stay in the text of the cells, as opaque text
+func GoTemplateAdapter(source string) string {
+	return templateAdapter(source, false)
+}
+
+// HandlebarsAdapter remove the comments and the block helpers of a handlebars template, like {{#each}} or {{/each}},
+// and the expressions inside the tags. The other expressions stay in the text of the cells, as opaque text
+func HandlebarsAdapter(source string) string {
+	return templateAdapter(source, true)
+}
+
+// templateAdapter replace the template actions that are not content by spaces
+func templateAdapter(source string, handlebars bool) string {
+	var output = []byte(source)
+	var inTag = false
+	var inComment = false
+	var quote = byte(0)
+
+	var i = 0
+	for i < len(source) {
+		if strings.HasPrefix(source[i:], "{{") {
+			var end = templateActionEnd(source, i, handlebars)
+			if end < 0 {
+				// An action without end is kept
+				break
+			}
+			if inTag || isTemplateControl(source[i:end], handlebars) {
+				blank(output, i, end)
+			}
+			i = end
+			continue
+		}
+
+		// Check for the html context of the action: a tag, a quoted attribute or a comment
+		var c = source[i]
+		switch {
+		case inComment:
+			if strings.HasPrefix(source[i:], "-->") {
+				inComment = false
+				i += 2
+			}
+		case inTag && quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case inTag:
+			if c == '"' || c == '\'' {
+				quote = c
+			} else if c == '>' {
+				inTag = false
+			}
+		case strings.HasPrefix(source[i:], "<!--"):
+			inComment = true
+			i += 3
+		case c == '<' && i+1 < len(source) && isTagStart(source[i+1]):
+			inTag = true
+		}
+		i++
+	}
+	return string(output)
+}
+
+// templateActionEnd return the offset after the end of the action that start at the offset, or -1
+func templateActionEnd(source string, start int, handlebars bool) int {
+	var body = source[start+2:]
+	var trimmed = strings.TrimLeft(body, "-~ \t\r\n")
+
+	var end = -1
+	switch {
+	case handlebars && strings.HasPrefix(body, "{"):
+		if index := strings.Index(body, "}}}"); index >= 0 {
+			end = index + 3
+		}
+	case handlebars && strings.HasPrefix(trimmed, "!--"):
+		end = indexAfter(body, "--}}", "--~}}")
+	case handlebars && strings.HasPrefix(trimmed, "!"):
+		end = indexAfter(body, "}}")
+	case handlebars == false && strings.HasPrefix(trimmed, "/*"):
+		if index := strings.Index(body, "*/"); index >= 0 {
+			if closing := strings.Index(body[index:], "}}"); closing >= 0 {
+				end = index + closing + 2
+			}
+		}
+	default:
+		// The strings of the action can contain braces
+		var quote = byte(0)
+		for i := 0; i < len(body); i++ {
+			var c = body[i]
+			if quote != 0 {
+				if c == '\\' && quote != '`' {
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+				continue
+			}
+			if c == '"' || c == '\'' || c == '`' {
+				quote = c
+			} else if strings.HasPrefix(body[i:], "}}") {
+				end = i + 2
+				break
+			}
+		}
+	}
+
+	if end < 0 {
+		return -1
+	}
+	return start + 2 + end
+}
+
+// indexAfter return the offset after the first of the strings found in the text, or -1
+func indexAfter(text string, values ...string) int {
+	var end = -1
+	for _, value := range values {
+		if index := strings.Index(text, value); index >= 0 && (end < 0 || index+len(value) < end) {
+			end = index + len(value)
+		}
+	}
+	return end
+}
+
+// isTemplateControl check if the action is a comment or a control action, that does not write any content
+func isTemplateControl(action string, handlebars bool) bool {
+	var body = strings.TrimLeft(action[2:], "{-~ \t\r\n")
+	if handlebars {
+		if len(body) > 0 && strings.IndexByte("#/^!", body[0]) >= 0 {
+			return true
+		}
+		return templateKeyword(body) == "else"
+	}
+
+	if strings.HasPrefix(body, "/*") {
+		return true
+	}
+	var keyword = templateKeyword(body)
+	for _, control := range goControlKeywords {
+		if keyword == control {
+			return true
+		}
+	}
+	return false
+}
+
+// templateKeyword return the first word of the action
+func templateKeyword(body string) string {
+	var end = strings.IndexAny(body, " \t\r\n}-~")
+	if end < 0 {
+		return body
+	}
+	return body[:end]
+}
+
+// isTagStart check if the character after a < start a tag, an end tag or a declaration
+func isTagStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '/' || c == '!'
+}
+
+// blank replace the characters by spaces, the line breaks are kept
+func blank(output []byte, start int, end int) {
+	for i := start; i < end; i++ {
+		if output[i] != '\n' && output[i] != '\r' {
+			output[i] = ' '
+		}
+	}
+}
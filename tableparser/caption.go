@@ -0,0 +1,337 @@
+package tableparser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// The elements displayed as blocks in a caption, they separate the caption from his description
+var captionBlockElements = map[string]bool{
+	"p": true, "div": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "dl": true, "blockquote": true, "pre": true, "figure": true, "section": true,
+	"details": true, "table": true, "address": true, "hr": true,
+}
+
+// The elements that never give a caption text
+var captionIgnoredElements = map[string]bool{"br": true, "wbr": true, "script": true, "template": true, "style": true}
+
+// Caption is the caption and the descriptions of a table, with the accessible name and description
+// computed like the browsers (HTML-AAM)
+type Caption struct {
+	// Element is the caption element, nil when the table does not have a caption
+	Element *goquery.Selection
+	// Text is the caption text without the description, see splitCaption
+	Text string
+	// Descriptions are the texts of the description blocks of the caption element, followed by the texts
+	// of the elements referenced by the aria-describedby attribute of the table and of the caption
+	Descriptions []string
+	// Summary is the legacy summary attribute of the table
+	Summary string
+	// AccessibleName is the name of the table from aria-labelledby, aria-label, the whole caption text or the title
+	AccessibleName string
+	// NameSource is the source of the accessible name: "aria-labelledby", "aria-label", "caption", "title" or empty
+	NameSource string
+	// AccessibleDescription is the description of the table from aria-describedby, aria-description,
+	// the title when it is not the name, or the summary attribute like the browsers that still support it
+	AccessibleDescription string
+}
+
+// TableCaption return the caption and the descriptions of the table, the table does not need to be parsed.
+// The caption is empty for an empty selection
+func TableCaption(table *goquery.Selection) Caption {
+	if table.Length() == 0 {
+		return Caption{Descriptions: []string{}}
+	}
+	var result = Caption{
+		Descriptions: []string{},
+		Summary:      normalizeText(table.AttrOr("summary", "")),
+	}
+
+	var element = table.ChildrenFiltered("caption").First()
+	var caption, description = tableDescription(table, element)
+	if element.Length() != 0 {
+		result.Element = element
+	}
+	if caption != nil {
+		result.Text = accessibleText(caption.Nodes)
+	}
+	for _, part := range description {
+		if text := accessibleText(part.Nodes); len(text) != 0 {
+			result.Descriptions = append(result.Descriptions, text)
+		}
+	}
+
+	result.AccessibleName, result.NameSource = accessibleName(table, element)
+	result.AccessibleDescription = accessibleDescription(table, result.NameSource)
+	return result
+}
+
+// accessibleName compute the name of the table, it return the name and his source
+func accessibleName(table *goquery.Selection, caption *goquery.Selection) (name string, source string) {
+	if name = referencedText(table, "aria-labelledby"); len(name) != 0 {
+		return name, "aria-labelledby"
+	}
+	if name = normalizeText(table.AttrOr("aria-label", "")); len(name) != 0 {
+		return name, "aria-label"
+	}
+	if caption.Length() != 0 {
+		if name = accessibleText(caption.Nodes); len(name) != 0 {
+			return name, "caption"
+		}
+	}
+	if name = normalizeText(table.AttrOr("title", "")); len(name) != 0 {
+		return name, "title"
+	}
+	return "", ""
+}
+
+// accessibleDescription compute the description of the table, the title is not used when it is the name
+func accessibleDescription(table *goquery.Selection, nameSource string) string {
+	if description := referencedText(table, "aria-describedby"); len(description) != 0 {
+		return description
+	}
+	if description := normalizeText(table.AttrOr("aria-description", "")); len(description) != 0 {
+		return description
+	}
+	if nameSource != "title" {
+		if description := normalizeText(table.AttrOr("title", "")); len(description) != 0 {
+			return description
+		}
+	}
+	return normalizeText(table.AttrOr("summary", ""))
+}
+
+// referencedText return the text of the elements referenced by the id list attribute
+func referencedText(element *goquery.Selection, name string) string {
+	var texts = []string{}
+	var root = documentRoot(element.Nodes[0])
+	for _, id := range strings.Fields(element.AttrOr(name, "")) {
+		if node := findElementByID(root, id); node != nil {
+			if text := accessibleText([]*html.Node{node}); len(text) != 0 {
+				texts = append(texts, text)
+			}
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// accessibleText return the text of the nodes with the white spaces collapsed, the images give their
+// text alternative and the block elements are separated by a space
+func accessibleText(nodes []*html.Node) string {
+	var builder = strings.Builder{}
+	var write func(node *html.Node)
+	write = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			builder.WriteString(node.Data)
+		case html.ElementNode:
+			if captionIgnoredElements[node.Data] && node.Data != "br" && node.Data != "wbr" {
+				return
+			}
+			if node.Data == "img" {
+				for _, attr := range node.Attr {
+					if attr.Key == "alt" {
+						builder.WriteString(attr.Val)
+					}
+				}
+			}
+			var block = captionBlockElements[node.Data] || node.Data == "br" || node.Data == "summary"
+			if block {
+				builder.WriteString(" ")
+			}
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				write(child)
+			}
+			if block {
+				builder.WriteString(" ")
+			}
+		}
+	}
+	for i, node := range nodes {
+		// The br elements skipped between the nodes still separate their texts
+		if i > 0 && hasLineBreakBetween(nodes[i-1], node) {
+			builder.WriteString(" ")
+		}
+		write(node)
+	}
+	return normalizeText(builder.String())
+}
+
+// hasLineBreakBetween check if there is a br element between the two sibling nodes
+func hasLineBreakBetween(first *html.Node, last *html.Node) bool {
+	for node := first.NextSibling; node != nil && node != last; node = node.NextSibling {
+		if isCaptionElement(node, "br") {
+			return true
+		}
+	}
+	return false
+}
+
+// tableDescription return the caption text and the description parts of the caption element, followed by
+// the elements referenced by the aria-describedby attribute of the table and of the caption element
+func tableDescription(table *goquery.Selection, element *goquery.Selection) (caption *goquery.Selection, description []*goquery.Selection) {
+	if element.Length() != 0 {
+		caption, description = splitCaption(element)
+	}
+	description = append(description, describedByElements(table)...)
+	if element.Length() != 0 {
+		description = append(description, describedByElements(element)...)
+	}
+	return caption, description
+}
+
+// splitCaption separate the caption text from the description in the caption element. The caption is the summary
+// of a details element, the first strong element (WET-BOEW technique), the first block element, or the inline content
+// before the first block element. The comments, the white spaces, the br elements and the empty elements are skipped.
+// The caption is nil when the caption element is empty
+func splitCaption(element *goquery.Selection) (caption *goquery.Selection, description []*goquery.Selection) {
+	var nodes = captionContent(element.Nodes[0])
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	var rest []*html.Node
+	var first = nodes[0]
+	switch {
+	case isCaptionElement(first, "details"):
+		// The summary is the caption, the rest of the details element is the description
+		var content = captionContent(first)
+		if len(content) != 0 && isCaptionElement(content[0], "summary") {
+			caption = element.FindNodes(content[0])
+			description = groupCaptionContent(element, content[1:])
+			return caption, append(description, groupCaptionContent(element, nodes[1:])...)
+		}
+		caption = element.FindNodes(first)
+		rest = nodes[1:]
+	case isCaptionElement(first, "strong") || isCaptionBlock(first):
+		caption = element.FindNodes(first)
+		rest = nodes[1:]
+	default:
+		var end = 0
+		for end < len(nodes) && isCaptionBlock(nodes[end]) == false {
+			end++
+		}
+		caption = element.FindNodes(nodes[:end]...)
+		rest = nodes[end:]
+	}
+
+	return caption, groupCaptionContent(element, rest)
+}
+
+// groupCaptionContent return the description parts, each block element is a part
+// and the inline content between the block elements is a part
+func groupCaptionContent(element *goquery.Selection, nodes []*html.Node) []*goquery.Selection {
+	var parts = []*goquery.Selection{}
+	var inline = []*html.Node{}
+	for _, node := range nodes {
+		if isCaptionBlock(node) {
+			if len(inline) != 0 {
+				parts = append(parts, element.FindNodes(inline...))
+				inline = []*html.Node{}
+			}
+			parts = append(parts, element.FindNodes(node))
+			continue
+		}
+		inline = append(inline, node)
+	}
+	if len(inline) != 0 {
+		parts = append(parts, element.FindNodes(inline...))
+	}
+	return parts
+}
+
+// captionContent return the child nodes that give a text: the comments, the white spaces,
+// the br elements and the elements without text are not returned
+func captionContent(parent *html.Node) []*html.Node {
+	var nodes = []*html.Node{}
+	for child := parent.FirstChild; child != nil; child = child.NextSibling {
+		switch child.Type {
+		case html.TextNode:
+			if len(strings.TrimSpace(child.Data)) != 0 {
+				nodes = append(nodes, child)
+			}
+		case html.ElementNode:
+			if captionIgnoredElements[child.Data] == false && hasCaptionText(child) {
+				nodes = append(nodes, child)
+			}
+		}
+	}
+	return nodes
+}
+
+// hasCaptionText check if the element have a text or an image with a text alternative
+func hasCaptionText(node *html.Node) bool {
+	if node.Type == html.TextNode {
+		return len(strings.TrimSpace(node.Data)) != 0
+	}
+	if node.Type != html.ElementNode || captionIgnoredElements[node.Data] {
+		return false
+	}
+	if node.Data == "img" {
+		for _, attr := range node.Attr {
+			if attr.Key == "alt" && len(strings.TrimSpace(attr.Val)) != 0 {
+				return true
+			}
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if hasCaptionText(child) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCaptionElement(node *html.Node, name string) bool {
+	return node.Type == html.ElementNode && node.Data == name
+}
+
+func isCaptionBlock(node *html.Node) bool {
+	return node.Type == html.ElementNode && captionBlockElements[node.Data]
+}
+
+// describedByElements return the elements referenced by the aria-describedby attribute of the element,
+// they are searched in the whole document
+func describedByElements(element *goquery.Selection) []*goquery.Selection {
+	var elements = []*goquery.Selection{}
+	var ids = strings.Fields(element.AttrOr("aria-describedby", ""))
+	if len(ids) == 0 {
+		return elements
+	}
+
+	var root = documentRoot(element.Nodes[0])
+	var document = goquery.NewDocumentFromNode(root).Selection
+	for _, id := range ids {
+		if node := findElementByID(root, id); node != nil {
+			elements = append(elements, document.FindNodes(node))
+		}
+	}
+	return elements
+}
+
+// documentRoot return the top node of the document of the node
+func documentRoot(node *html.Node) *html.Node {
+	for node.Parent != nil {
+		node = node.Parent
+	}
+	return node
+}
+
+// findElementByID return the first element with the id in the document order, or nil
+func findElementByID(node *html.Node, id string) *html.Node {
+	if node.Type == html.ElementNode {
+		for _, attr := range node.Attr {
+			if attr.Key == "id" && attr.Val == id {
+				return node
+			}
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElementByID(child, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
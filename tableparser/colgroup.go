@@ -0,0 +1,134 @@
+package tableparser
+
+import (
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ColGroupNode is a column group in the column hierarchy of the table
+type ColGroupNode struct {
+	// Type is TypeHeader, TypeData or TypeSummary for a colgroup,
+	// TypeGroupHeader for the virtual group made by a group header cell
+	Type Type
+	// Level of the column group, the lowest level are the most generic.
+	// The header colgroup and the colgroup that totals the whole table are at level 0
+	Level int
+	// Start and End are the column positions covered by the group, starting at 1
+	Start int
+	End   int
+	// Headers are the header cells of the group
+	Headers []Cell
+	// Selection is the colgroup element, it is nil for a virtual group
+	Selection *goquery.Selection
+	// Columns are the columns directly inside a colgroup
+	Columns  []*Column
+	Parent   *ColGroupNode
+	Children []*ColGroupNode
+}
+
+// Virtual return true when the group was not defined by a colgroup element
+func (n *ColGroupNode) Virtual() bool {
+	return n.Selection == nil
+}
+
+// Column is a column of the table with the headers that represent it
+type Column struct {
+	Type  Type
+	Level int
+	// Start and End are the column positions, they are different when a col element is spanned
+	Start int
+	End   int
+	// Selection is the col element, if any
+	Selection *goquery.Selection
+	// Headers are the header cells of the column, from the generic to the specific
+	Headers []Cell
+	// GroupHeaders are the group header cells above the column
+	GroupHeaders []Cell
+	// SummaryHeaders are the header cells of the tfoot rows
+	SummaryHeaders []Cell
+	ColGroup       *ColGroupNode
+}
+
+// buildColGroupTree convert the colgroup frame, the virtual colgroup and the columns into the column hierarchy
+func buildColGroupTree(colgroups []ColGroup, virtualColgroups []Cell, columns []ColGroup) ([]*ColGroupNode, []*Column) {
+	var nodes = []*ColGroupNode{}
+	var groupNodes = []*ColGroupNode{}
+	var colNodes = []*Column{}
+
+	// Group header cells, they are the virtual colgroup at the higher levels
+	for _, cell := range virtualColgroups {
+		nodes = append(nodes, &ColGroupNode{
+			Type:     TypeGroupHeader,
+			Level:    cell.level,
+			Start:    cell.start,
+			End:      cell.end,
+			Headers:  []Cell{cell},
+			Columns:  []*Column{},
+			Children: []*ColGroupNode{},
+		})
+	}
+
+	for _, colgroup := range colgroups {
+		var node = &ColGroupNode{
+			Type:      Type(colgroup.etype),
+			Level:     colgroup.level,
+			Start:     colgroup.start,
+			End:       colgroup.end,
+			Headers:   colgroup.header,
+			Selection: colgroup.elem,
+			Columns:   []*Column{},
+			Children:  []*ColGroupNode{},
+		}
+		if node.Type == TypeUnknown {
+			node.Type = TypeData
+		}
+		nodes = append(nodes, node)
+		groupNodes = append(groupNodes, node)
+	}
+
+	// The parent is the smallest group header that contains the group
+	for _, node := range nodes {
+		for _, candidate := range nodes {
+			if candidate == node || candidate.Type != TypeGroupHeader || candidate.Level >= node.Level {
+				continue
+			}
+			if candidate.Start > node.Start || candidate.End < node.End {
+				continue
+			}
+			if node.Parent == nil || candidate.Level > node.Parent.Level {
+				node.Parent = candidate
+			}
+		}
+		if node.Parent != nil {
+			node.Parent.Children = append(node.Parent.Children, node)
+		}
+	}
+
+	for _, col := range columns {
+		var column = &Column{
+			Type:           Type(col.etype),
+			Level:          col.level,
+			Start:          col.start,
+			End:            col.end,
+			Selection:      col.elem,
+			Headers:        col.header,
+			GroupHeaders:   col.headerLevel,
+			SummaryHeaders: col.summaryheader,
+		}
+		for _, node := range groupNodes {
+			if node.Start <= column.Start && column.End <= node.End {
+				column.ColGroup = node
+				node.Columns = append(node.Columns, column)
+				if column.Type == TypeUnknown {
+					column.Type = node.Type
+				}
+				if column.Level == 0 {
+					column.Level = node.Level
+				}
+				break
+			}
+		}
+		colNodes = append(colNodes, column)
+	}
+
+	return nodes, colNodes
+}
@@ -0,0 +1,69 @@
+package tableparser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DescribedByAttribute is the attribute set by the describedby fix
+const DescribedByAttribute = "aria-describedby"
+
+// Prefix of the id generated for the description and key cells
+const generatedIDPrefix = "tblparser-"
+
+var generatedIDCount int
+
+// Describes return the header cells described by a description cell, or represented by a key cell
+func (c Cell) Describes() []Cell {
+	return c.describe
+}
+
+// linkDescribedBy set the aria-describedby attribute of each header cell
+// with the id of his description cells and key cells
+func linkDescribedBy(cells []Cell) {
+	for _, cell := range cells {
+		if cell.elem == nil || len(cell.describe) == 0 {
+			continue
+		}
+		var id = ensureID(cell.elem)
+		for _, header := range cell.describe {
+			if header.elem != nil {
+				addAttrToken(header.elem, DescribedByAttribute, id)
+			}
+		}
+	}
+}
+
+// ensureID return the id of the element, an unique id is generated if the element does not have one
+func ensureID(elem *goquery.Selection) string {
+	if id, exists := elem.Attr("id"); exists == true && len(strings.TrimSpace(id)) != 0 {
+		return strings.TrimSpace(id)
+	}
+
+	var root = elem.Parents().Last()
+	var id string
+	for {
+		generatedIDCount++
+		id = generatedIDPrefix + strconv.Itoa(generatedIDCount)
+		if root.Find("#"+id).Length() == 0 {
+			break
+		}
+	}
+	elem.SetAttr("id", id)
+
+	return id
+}
+
+// addAttrToken add the token in a space separated attribute if it is not already there
+func addAttrToken(elem *goquery.Selection, attrName string, token string) {
+	var attrVal, _ = elem.Attr(attrName)
+	var tokens = strings.Fields(attrVal)
+	for _, existing := range tokens {
+		if existing == token {
+			return
+		}
+	}
+	elem.SetAttr(attrName, strings.Join(append(tokens, token), " "))
+}
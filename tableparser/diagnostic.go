@@ -0,0 +1,136 @@
+package tableparser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Severity of a diagnostic
+type Severity int
+
+// Severity values, the zero value is used to keep the default severity of a rule
+const (
+	SeverityDefault Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"default", "info", "warning", "error"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return severityNames[SeverityDefault]
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity return the severity from his name, ok is false when the name is unknown
+func ParseSeverity(name string) (severity Severity, ok bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, severityName := range severityNames {
+		if severityName == name {
+			return Severity(i), true
+		}
+	}
+	return SeverityDefault, false
+}
+
+// Diagnostic is a problem found by the parser
+type Diagnostic struct {
+	Rule     Rule
+	Severity Severity
+	// Message is the message in the language of the options, see Options.Lang
+	Message string
+	// Key is the message key of the catalogs, it is the rule id
+	Key string
+	// Args are the values written in the message, by name
+	Args map[string]string
+	// Selection is the element where the problem was found, it can be nil
+	Selection *goquery.Selection
+}
+
+// Error return the diagnostic as "severity<TAB>code<TAB>message"
+func (d *Diagnostic) Error() string {
+	return d.Severity.String() + "\t" + strconv.Itoa(d.Rule.Code) + "\t" + d.Message
+}
+
+// Diagnostics is the list of problems found in a table
+type Diagnostics []*Diagnostic
+
+// Error return one diagnostic per line
+func (d Diagnostics) Error() string {
+	var lines = []string{}
+	for _, diag := range d {
+		lines = append(lines, diag.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newDiagnostic create the diagnostic for the rule code, it is returned when the parser stop on the problem
+func newDiagnostic(code int, elem *goquery.Selection) error {
+	return newDiagnosticArgs(code, elem, nil)
+}
+
+// newDiagnosticArgs create the diagnostic for the rule code with the arguments of the message
+func newDiagnosticArgs(code int, elem *goquery.Selection, args map[string]string) error {
+	var rule, _ = RuleByCode(code)
+	var severity = rule.Severity
+	if override, exists := options.Severities[code]; exists == true && override != SeverityDefault {
+		severity = override
+	}
+
+	return &Diagnostic{
+		Rule:      rule,
+		Severity:  severity,
+		Message:   formatMessage(rule, args),
+		Key:       rule.ID,
+		Args:      args,
+		Selection: elem,
+	}
+}
+
+// addDiagnostic record the diagnostic for the rule code, the parser continue after it
+func addDiagnostic(code int, elem *goquery.Selection) {
+	addDiagnosticArgs(code, elem, nil)
+}
+
+// addDiagnosticArgs record the diagnostic for the rule code with the arguments of the message
+func addDiagnosticArgs(code int, elem *goquery.Selection, args map[string]string) {
+	groupZero.diagnostics = append(groupZero.diagnostics, newDiagnosticArgs(code, elem, args).(*Diagnostic))
+}
+
+// isRuleEnabled check the rule code against the enabled and disabled rules of the options
+func isRuleEnabled(code int) bool {
+	if len(options.EnabledRules) > 0 {
+		var enabled = false
+		for _, enabledCode := range options.EnabledRules {
+			if enabledCode == code {
+				enabled = true
+			}
+		}
+		if enabled == false {
+			return false
+		}
+	}
+
+	for _, disabledCode := range options.DisabledRules {
+		if disabledCode == code {
+			return false
+		}
+	}
+	return true
+}
+
+// reportedDiagnostics return the diagnostics of the enabled rules
+func reportedDiagnostics(diagnostics []*Diagnostic) Diagnostics {
+	var reported = Diagnostics{}
+	for _, diag := range diagnostics {
+		if isRuleEnabled(diag.Rule.Code) == true {
+			reported = append(reported, diag)
+		}
+	}
+	return reported
+}
@@ -0,0 +1,122 @@
+package tableparser
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// Result is the parsed table of a document
+type Result struct {
+	// Index is the position of the table in the validated tables, starting at 1
+	Index int
+	// Table is the parsed structure of the table
+	Table *Table
+	// Err are the problems of the table, see Parse
+	Err error
+}
+
+// ParseReader read the html document and parse all the tables matched by the selector,
+// all the tables are parsed when the selector is empty
+func ParseReader(r io.Reader, opts Options, selector string) ([]Result, error) {
+	return ParseReaderContext(context.Background(), r, opts, selector)
+}
+
+// ParseReaderContext is ParseReader with a context, see ParseSelectionContext
+func ParseReaderContext(ctx context.Context, r io.Reader, opts Options, selector string) ([]Result, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSelectionContext(ctx, doc.Selection, opts, selector)
+}
+
+// ParseString parse all the tables of the html string matched by the selector
+func ParseString(htmlstring string, opts Options, selector string) ([]Result, error) {
+	return ParseReaderContext(context.Background(), strings.NewReader(htmlstring), opts, selector)
+}
+
+// ParseStringContext is ParseString with a context, see ParseSelectionContext
+func ParseStringContext(ctx context.Context, htmlstring string, opts Options, selector string) ([]Result, error) {
+	return ParseReaderContext(ctx, strings.NewReader(htmlstring), opts, selector)
+}
+
+// ParseNode parse the tables of a document node, an element or a table element, matched by the selector
+func ParseNode(node *html.Node, opts Options, selector string) ([]Result, error) {
+	return ParseNodeContext(context.Background(), node, opts, selector)
+}
+
+// ParseNodeContext is ParseNode with a context, see ParseSelectionContext
+func ParseNodeContext(ctx context.Context, node *html.Node, opts Options, selector string) ([]Result, error) {
+	return ParseSelectionContext(ctx, goquery.NewDocumentFromNode(node).Selection, opts, selector)
+}
+
+// ParseSelection parse the tables of the selection matched by the selector.
+// When the selector match other elements than the tables, the tables inside those elements are parsed
+func ParseSelection(root *goquery.Selection, opts Options, selector string) ([]Result, error) {
+	return ParseSelectionContext(context.Background(), root, opts, selector)
+}
+
+// ParseSelectionContext parse the tables like ParseSelection, it stop with the context error
+// when the context is canceled, the results of the tables already parsed are returned.
+// It is safe for concurrent use, the documents are parsed one at a time
+func ParseSelectionContext(ctx context.Context, root *goquery.Selection, opts Options, selector string) ([]Result, error) {
+	parserMutex.Lock()
+	defer parserMutex.Unlock()
+	return parseSelectionLocked(ctx, root, opts, selector)
+}
+
+// parseSelectionLocked is ParseSelectionContext, with the parserMutex locked
+func parseSelectionLocked(ctx context.Context, root *goquery.Selection, opts Options, selector string) ([]Result, error) {
+	var results = []Result{}
+
+	tables, err := findTables(root, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	tables.EachWithBreak(func(index int, element *goquery.Selection) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+
+		table, tableErr := parseLocked(ctx, element, opts)
+		if tableErr == ctx.Err() && tableErr != nil {
+			// The table was not completely parsed
+			err = tableErr
+			return false
+		}
+		results = append(results, Result{
+			Index: index + 1,
+			Table: table,
+			Err:   tableErr,
+		})
+		return true
+	})
+	return results, err
+}
+
+// findTables return the tables of the root and his descendants matched by the selector, in the document order
+func findTables(root *goquery.Selection, selector string) (*goquery.Selection, error) {
+	if len(strings.TrimSpace(selector)) == 0 {
+		selector = "table"
+	}
+
+	// An invalid selector panic in goquery, it is returned as an error
+	var matcher, err = cascadia.Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched = root.FilterMatcher(matcher).AddSelection(root.FindMatcher(matcher))
+	var tables = matched.Filter("table")
+	var containers = matched.Not("table")
+	tables = tables.AddSelection(containers.Find("table"))
+
+	// Keep the document order, AddSelection append the nodes at the end
+	return root.Filter("table").AddSelection(root.Find("table")).FilterSelection(tables), nil
+}
@@ -0,0 +1,159 @@
+package tableparser
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Length of the fingerprint, in hexadecimal characters
+const fingerprintLength = 12
+
+// Fingerprint return a stable identifier of the table, computed from his id, his caption text
+// and his header structure: the header rows and the text of the row header cells. It does not change
+// when an other table is added to the page or when data rows without row header are added to the table.
+// The tables with the same structure in a document, like the tables without id, caption and header cells
+// or the copies of a template table, are numbered in the document order: the first one keep the fingerprint,
+// the second one end with "-2". The fingerprint of an empty selection is empty
+func Fingerprint(table *goquery.Selection) string {
+	parserMutex.Lock()
+	defer parserMutex.Unlock()
+	return tableFingerprint(table)
+}
+
+// tableFingerprint is Fingerprint, with the parserMutex locked
+func tableFingerprint(table *goquery.Selection) string {
+	if table.Length() == 0 {
+		return ""
+	}
+	var fingerprint = tableIdentity(table)
+
+	var ordinal = 1
+	var document = goquery.NewDocumentFromNode(documentRoot(table.Nodes[0]))
+	document.Find("table").EachWithBreak(func(index int, other *goquery.Selection) bool {
+		if other.Nodes[0] == table.Nodes[0] {
+			return false
+		}
+		if tableIdentity(other) == fingerprint {
+			ordinal++
+		}
+		return true
+	})
+	return numberedFingerprint(fingerprint, ordinal)
+}
+
+// numberedFingerprint add the number of the table among the tables with the same identity, the first one is not numbered
+func numberedFingerprint(identity string, ordinal int) string {
+	if ordinal > 1 {
+		return identity + "-" + strconv.Itoa(ordinal)
+	}
+	return identity
+}
+
+// tableIdentity return the fingerprint of the table without his number among the tables with the same structure
+func tableIdentity(table *goquery.Selection) string {
+	var identity = newIdentityWriter(table)
+	table.ChildrenFiltered("thead, tbody, tfoot, tr").Each(func(index int, elem *goquery.Selection) {
+		var rows = elem
+		if goquery.NodeName(elem) != "tr" {
+			rows = elem.ChildrenFiltered("tr")
+		}
+		rows.Each(func(index int, row *goquery.Selection) {
+			identity.addRow(row)
+		})
+	})
+	return identity.sum()
+}
+
+// identityWriter compute the identity of a table one row at a time, StreamReader does not keep the rows
+type identityWriter struct {
+	hash       hash.Hash
+	headerRows bool
+}
+
+// newIdentityWriter start the identity with the id and the caption text of the table
+func newIdentityWriter(table *goquery.Selection) *identityWriter {
+	var identity = &identityWriter{
+		hash:       sha1.New(),
+		headerRows: true,
+	}
+	io.WriteString(identity.hash, "id:"+strings.TrimSpace(table.AttrOr("id", "")))
+	identity.write("caption:" + normalizeText(table.ChildrenFiltered("caption").Text()))
+	return identity
+}
+
+// addRow add the row, in the document order. The header rows are the rows at the top of the table
+// with only header cells and empty cells, the other rows give the text of their row header cells
+func (identity *identityWriter) addRow(row *goquery.Selection) {
+	if identity.headerRows == true && isHeaderRow(row) == true {
+		identity.write("row:" + headerRowStructure(row))
+		return
+	}
+	identity.headerRows = false
+	if headers := rowHeaderTexts(row); len(headers) != 0 {
+		identity.write("rowheader:" + headers)
+	}
+}
+
+// write add a line to the identity
+func (identity *identityWriter) write(part string) {
+	io.WriteString(identity.hash, "\n"+part)
+}
+
+// sum return the identity of the rows added so far
+func (identity *identityWriter) sum() string {
+	return hex.EncodeToString(identity.hash.Sum(nil))[:fingerprintLength]
+}
+
+// isHeaderRow check if the row only have header cells and empty cells
+func isHeaderRow(row *goquery.Selection) bool {
+	var cells = row.ChildrenFiltered("th, td")
+	if cells.Length() == 0 {
+		return false
+	}
+	var header = true
+	cells.EachWithBreak(func(index int, cell *goquery.Selection) bool {
+		if goquery.NodeName(cell) == "td" && len(strings.TrimSpace(cell.Text())) != 0 {
+			header = false
+		}
+		return header
+	})
+	return header
+}
+
+// headerRowStructure write the cell types, spans and header text of the row
+func headerRowStructure(row *goquery.Selection) string {
+	var cells = []string{}
+	row.ChildrenFiltered("th, td").Each(func(index int, cell *goquery.Selection) {
+		cells = append(cells, strings.Join([]string{
+			goquery.NodeName(cell),
+			cell.AttrOr("colspan", "1"),
+			cell.AttrOr("rowspan", "1"),
+			normalizeText(cell.Text()),
+		}, ","))
+	})
+	return strings.Join(cells, "|")
+}
+
+// rowHeaderTexts write the text of the header cells in the leading columns of the row
+func rowHeaderTexts(row *goquery.Selection) string {
+	var texts = []string{}
+	row.ChildrenFiltered("th, td").EachWithBreak(func(index int, cell *goquery.Selection) bool {
+		if goquery.NodeName(cell) != "th" {
+			return false
+		}
+		texts = append(texts, normalizeText(cell.Text()))
+		return true
+	})
+	return strings.Join(texts, "|")
+}
+
+// normalizeText collapse the white spaces of the text
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
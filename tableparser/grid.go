@@ -0,0 +1,276 @@
+package tableparser
+
+import (
+	"strconv"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Grid return the slots of the table, one line per row and one cell per column, the tfoot rows are at the end.
+// A cell that span several slots is repeated in each of them, a slot without cell have a nil Selection.
+// The cells are placed at the positions computed by the parser. The rows after the row where the parser
+// stopped are placed like the html table model, their cells have the TypeUnknown type.
+// The grid is built on the first call, it is empty when the table is too large to be parsed
+func (t *Table) Grid() [][]Cell {
+	if t.grid == nil {
+		t.grid = buildGrid(t.gridSource)
+		t.gridSource = gridSource{}
+	}
+	return t.grid
+}
+
+// gridSource is what the grid is built from, it is collected when the table is parsed
+// and it keep one entry per cell and per row, instead of one per slot
+type gridSource struct {
+	// rows are the row groups of each row, in the grid order
+	rows []int
+	// cells are the cells read by the parser
+	cells []Cell
+	// unread are the cell elements of the rows after the rows read by the parser
+	unread []*goquery.Selection
+	types  map[*html.Node]Type
+}
+
+// The cells of the rows read by the parser, and the number of rows read, the cells are kept for the grid
+var readCells = []Cell{}
+var readRowCount = 0
+
+// addReadRow keep the cells that start in the row, a spanned cell is in the slots of several rows
+func addReadRow(row Row) {
+	readRowCount++
+	for i, cell := range row.cell {
+		if cell.rowpos == row.rowpos && (i == 0 || row.cell[i-1].uid != cell.uid) {
+			readCells = append(readCells, cell)
+		}
+	}
+}
+
+// newGridSource collect the rows of the table in the parser order and the cells read by the parser
+func newGridSource(table *goquery.Selection) gridSource {
+	var source = gridSource{
+		rows:   []int{},
+		cells:  readCells,
+		unread: []*goquery.Selection{},
+		types:  cellTypes(),
+	}
+
+	// The rows directly in the table make one row group
+	var group = 0
+	var directRows = false
+	structureChildren(table).Not("tfoot").AddSelection(structureChildren(table, "tfoot").First()).Each(func(index int, elem *goquery.Selection) {
+		var rows = elem
+		switch goquery.NodeName(elem) {
+		case "thead", "tbody", "tfoot":
+			directRows = false
+			group++
+			rows = structureChildren(elem, "tr")
+		case "tr":
+			if directRows == false {
+				directRows = true
+				group++
+			}
+		default:
+			return
+		}
+
+		rows.Each(func(rowIndex int, row *goquery.Selection) {
+			source.rows = append(source.rows, group)
+			if len(source.rows) > readRowCount {
+				source.unread = append(source.unread, structureChildren(row, "th", "td"))
+			}
+		})
+	})
+
+	return source
+}
+
+// cellTypes collect the type computed by the parser for each cell element
+func cellTypes() map[*html.Node]Type {
+	var types = map[*html.Node]Type{}
+	var setType = func(cell Cell, etype int) {
+		if cell.elem != nil && cell.elem.Length() != 0 && etype != 0 {
+			types[cell.elem.Nodes[0]] = Type(etype)
+		}
+	}
+
+	for _, row := range theadRowStack {
+		for _, cell := range row.cell {
+			setType(cell, cell.etype)
+		}
+	}
+	for _, row := range groupZero.row {
+		for _, cell := range row.cell {
+			setType(cell, cell.etype)
+		}
+	}
+
+	// The specific cell types have priority on the row types
+	for _, rowgroup := range lstRowGroup {
+		for _, cell := range rowgroup.headerlevel {
+			setType(cell, 7)
+		}
+	}
+	for _, cell := range groupZero.virtualColgroup {
+		setType(cell, 7)
+	}
+	for _, cell := range groupZero.desccell {
+		setType(cell, 5)
+	}
+	for _, cell := range groupZero.keycell {
+		setType(cell, 4)
+	}
+	for _, cell := range groupZero.layoutCell {
+		setType(cell, 6)
+	}
+	return types
+}
+
+// buildGrid place the cells read by the parser at their positions, then the cells of the unread rows like
+// the html table model. The cell spans do not go beyond their row group
+func buildGrid(source gridSource) [][]Cell {
+	var grid = make([][]Cell, len(source.rows))
+
+	// The last line of the row group of each line
+	var groupEnd = make([]int, len(source.rows))
+	for y := len(source.rows) - 1; y >= 0; y-- {
+		groupEnd[y] = y
+		if y+1 < len(source.rows) && source.rows[y+1] == source.rows[y] {
+			groupEnd[y] = groupEnd[y+1]
+		}
+	}
+
+	var place = func(cell Cell) {
+		var y = cell.rowpos - 1
+		if y < 0 || y >= len(grid) || cell.colpos < 1 {
+			return
+		}
+		if cell.height <= 0 || y+cell.height-1 > groupEnd[y] {
+			// A rowspan of 0 span the rest of the row group
+			cell.height = groupEnd[y] - y + 1
+		}
+		if cell.elem != nil && cell.elem.Length() != 0 {
+			cell.etype = int(source.types[cell.elem.Nodes[0]])
+		}
+		for line := y; line < y+cell.height; line++ {
+			for len(grid[line]) < cell.colpos-1+cell.width {
+				grid[line] = append(grid[line], Cell{})
+			}
+			for x := cell.colpos - 1; x < cell.colpos-1+cell.width; x++ {
+				grid[line][x] = cell
+			}
+		}
+	}
+
+	for _, cell := range source.cells {
+		place(cell)
+	}
+
+	var first = len(source.rows) - len(source.unread)
+	for i, cells := range source.unread {
+		var y = first + i
+		var colIndex = 0
+		cells.Each(func(index int, elem *goquery.Selection) {
+			for colIndex < len(grid[y]) && grid[y][colIndex].elem != nil {
+				colIndex++
+			}
+			var cell = Cell{
+				elem:   elem,
+				rowpos: y + 1,
+				colpos: colIndex + 1,
+				width:  spanValue(elem, "colspan", 1),
+				height: spanValue(elem, "rowspan", 1),
+			}
+			place(cell)
+			colIndex = colIndex + cell.width
+		})
+	}
+
+	return grid
+}
+
+// spanValue return the colspan or rowspan attribute value
+func spanValue(elem *goquery.Selection, name string, defaultValue int) int {
+	var value, err = strconv.Atoi(elem.AttrOr(name, strconv.Itoa(defaultValue)))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	if name == "colspan" && value == 0 {
+		return 1
+	}
+	return value
+}
+
+// Headers return the header cells of a cell, from the header lists computed by the parser: the group
+// header cells and the header cells of his columns, then the row group header cells and the row header
+// cells of his rows. A cell not read by the parser, or a cell of a too large table, have no headers
+func (t *Table) Headers(cell Cell) []Cell {
+	var headers = []Cell{}
+	if cell.elem == nil || cell.elem.Length() == 0 {
+		return headers
+	}
+	var rowHeaders, found = t.rowHeaders[cell.elem.Nodes[0]]
+	if found == false {
+		return headers
+	}
+
+	var seen = map[*html.Node]bool{cell.elem.Nodes[0]: true}
+	var addHeaders = func(cells []Cell, before func(header Cell) bool) {
+		for _, header := range cells {
+			if header.elem == nil || header.elem.Length() == 0 || seen[header.elem.Nodes[0]] == true {
+				continue
+			}
+			if before(header) == true {
+				seen[header.elem.Nodes[0]] = true
+				headers = append(headers, header)
+			}
+		}
+	}
+
+	// Column headers, only the ones above the cell for a cell of the thead
+	var above = func(header Cell) bool {
+		return header.rowpos < cell.rowpos
+	}
+	for _, column := range t.Columns {
+		if column.End >= cell.colpos && column.Start <= cell.colpos+cell.width-1 {
+			addHeaders(column.GroupHeaders, above)
+			addHeaders(column.Headers, above)
+		}
+	}
+
+	// Row headers, only the ones before the cell for a row header cell
+	addHeaders(rowHeaders, func(header Cell) bool {
+		return header.etype == 7 || header.colpos < cell.colpos
+	})
+	return headers
+}
+
+// cellRowHeaders collect the row headers computed by the parser for each cell, the row group header cells
+// and the row header cells of the row where the cell start, and the additional row headers of a spanned cell.
+// The thead cells are in, without row headers
+func cellRowHeaders() map[*html.Node][]Cell {
+	var rowHeaders = map[*html.Node][]Cell{}
+	for _, row := range theadRowStack {
+		for _, cell := range row.cell {
+			if cell.elem != nil && cell.elem.Length() != 0 {
+				rowHeaders[cell.elem.Nodes[0]] = []Cell{}
+			}
+		}
+	}
+
+	for _, row := range groupZero.row {
+		var headers = append(append([]Cell{}, row.headerset...), row.header...)
+		for i, cell := range row.cell {
+			if cell.elem == nil || cell.elem.Length() == 0 || (i > 0 && row.cell[i-1].uid == cell.uid) {
+				continue
+			}
+			var node = cell.elem.Nodes[0]
+			if cell.rowpos == row.rowpos {
+				rowHeaders[node] = headers
+			} else if len(cell.addrowheaders) > 0 {
+				rowHeaders[node] = append(append([]Cell{}, rowHeaders[node]...), cell.addrowheaders...)
+			}
+		}
+	}
+	return rowHeaders
+}
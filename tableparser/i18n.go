@@ -0,0 +1,150 @@
+package tableparser
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLang is the language of the messages when no language is set
+const DefaultLang = "en"
+
+// Catalog are the diagnostic messages of a language, by message key.
+// The message key is the rule id, the arguments are written as {name} in the message
+type Catalog map[string]string
+
+// The messages that use arguments, the other english messages are the rule messages
+var englishMessages = Catalog{
+	"row-width":       "The row do not have a good width, it have {width} cells instead of {expected}",
+	"tfoot-row-width": "The tfoot row do not have the same width as the table body, it have {width} cells instead of {expected}",
+	"max-rows":        "The table have too many rows to be validated, {value} rows for a limit of {limit}",
+	"max-columns":     "The table have too many columns to be validated, {value} columns for a limit of {limit}",
+	"max-slots":       "The table have too many spanned slots to be validated, {value} slots for a limit of {limit}",
+	"ignored-markup":  "The {element} markup is ignored in the table structure",
+}
+
+var frenchMessages = Catalog{
+	"colgroup-header-span":          "Le premier colgroup doit couvrir les colonnes du groupe d'en-têtes",
+	"description-row-cell":          "Une cellule invalide se trouve dans une rangée de description",
+	"data-colgroup-missing":         "Il faut au moins un colgroup de données, révisez la structure du tableau",
+	"colgroup-lowest-level":         "Le niveau le plus bas des groupes de colonnes a déjà été trouvé, la structure des colonnes contient peut-être une erreur",
+	"header-colgroup-width":         "Le colgroup initial devrait regrouper tous les en-têtes, il ne reste aucune place pour les cellules de données",
+	"header-cell-crossing-colgroup": "Erreur dans le groupe de rangées d'en-tête, des cellules chevauchent plus d'un colgroup",
+	"group-header-cell-encapsulate": "La cellule d'en-tête de groupe qui représente les données d'un niveau doit englober son groupe",
+	"summary-rowgroup-last":         "Le dernier groupe de rangées de sommaire a déjà été trouvé",
+	"rowgroup-not-calculated":       "Erreur, le groupe de rangées n'a pas été calculé",
+	"rowgroup-level":                "Le niveau du groupe de rangées ne peut pas être calculé",
+	"row-child":                     "L'élément tr doit seulement contenir des éléments th ou td",
+	"row-width":                     "La rangée n'a pas la bonne largeur, elle a {width} cellules au lieu de {expected}",
+	"layout-cell-not-empty":         "La cellule de mise en page n'est pas vide",
+	"rowgroup-header-structure":     "L'en-tête du groupe de rangées n'est pas bien structuré",
+	"header-row-in-tbody":           "Déplacez la rangée utilisée comme en-tête de colonne dans le groupe de rangées thead",
+	"parallel-row-headers":          "Évitez les en-têtes de rangée parallèles, il est recommandé de fusionner les cellules",
+	"row-header-hierarchy":          "Pour une rangée de données, la hiérarchie des en-têtes doit aller du général au particulier",
+	"problematic-key-cell":          "Une cellule clé est problématique",
+	"row-before-thead":              "Aucune rangée ne peut être définie avant le groupe thead",
+	"rowgroup-child":                "Les éléments thead, tbody et tfoot doivent seulement contenir des éléments tr",
+	"rowspan-across-rowgroup":       "Une cellule ne peut pas s'étendre sur deux groupes de rangées",
+	"table-markup":                  "Utilisez le balisage de tableau approprié",
+	"virtual-column":                "Erreur interne, le nombre de colonnes virtuelles doit être défini [func processColgroup()]",
+	"row-header-structure":          "Vérifiez la structure des cellules d'en-tête de rangée",
+	"data-rowgroup-mark":            "Identifiez correctement le groupe de rangées de données",
+	"col-definition":                "Les colonnes, éléments col, ne sont pas définies correctement",
+	"multiple-tfoot":                "Un tableau ne peut avoir qu'un seul élément tfoot",
+	"tfoot-row-width":               "La rangée du tfoot n'a pas la même largeur que le corps du tableau, elle a {width} cellules au lieu de {expected}",
+	"layout-cell-th":                "La cellule de mise en page doit être un élément td, pas un élément th vide",
+	"layout-cell-span":              "La cellule de mise en page ne peut pas s'étendre sur les colonnes de données",
+	"max-rows":                      "Le tableau a trop de rangées pour être validé, {value} rangées pour une limite de {limit}",
+	"max-columns":                   "Le tableau a trop de colonnes pour être validé, {value} colonnes pour une limite de {limit}",
+	"max-slots":                     "Le tableau a trop de cases fusionnées pour être validé, {value} cases pour une limite de {limit}",
+	"ignored-markup":                "Le balisage {element} est ignoré dans la structure du tableau",
+}
+
+var catalogs = map[string]Catalog{
+	"en": englishMessages,
+	"fr": frenchMessages,
+}
+
+// The catalogs are read by the parsers and can be registered at the same time, like in a server
+var catalogsMutex sync.RWMutex
+
+// RegisterCatalog add the messages of a language. The messages are merged with the messages already
+// registered for the language, a message with the same key is replaced and the other messages are kept
+func RegisterCatalog(lang string, catalog Catalog) {
+	catalogsMutex.Lock()
+	defer catalogsMutex.Unlock()
+
+	lang = normalizeLang(lang)
+	var merged = Catalog{}
+	for key, message := range catalogs[lang] {
+		merged[key] = message
+	}
+	for key, message := range catalog {
+		merged[key] = message
+	}
+	catalogs[lang] = merged
+}
+
+// Langs return the languages of the registered catalogs
+func Langs() []string {
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	var langs = []string{}
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// HasLang check if a catalog is registered for the language
+func HasLang(lang string) bool {
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	var _, exists = catalogs[normalizeLang(lang)]
+	return exists
+}
+
+// LoadCatalog read a catalog written as a json object of message key and message
+func LoadCatalog(r io.Reader) (Catalog, error) {
+	var catalog = Catalog{}
+	var err = json.NewDecoder(r).Decode(&catalog)
+	return catalog, err
+}
+
+// normalizeLang keep the primary language of a tag like "fr-CA"
+func normalizeLang(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if index := strings.IndexAny(lang, "-_"); index > 0 {
+		lang = lang[:index]
+	}
+	return lang
+}
+
+// formatMessage return the message of the rule in the language of the options,
+// the english message and then the rule message are used when the message is not translated
+func formatMessage(rule Rule, args map[string]string) string {
+	var message, exists = options.Catalog[rule.ID]
+
+	catalogsMutex.RLock()
+	if exists == false {
+		message, exists = catalogs[normalizeLang(options.Lang)][rule.ID]
+	}
+	if exists == false {
+		message, exists = catalogs[DefaultLang][rule.ID]
+	}
+	catalogsMutex.RUnlock()
+
+	if exists == false {
+		message = rule.Message
+	}
+
+	for name, value := range args {
+		message = strings.Replace(message, "{"+name+"}", value, -1)
+	}
+	return message
+}
@@ -0,0 +1,41 @@
+package tableparser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// The elements that the html parser keep in the table structure, they are ignored in the lenient mode:
+// the script-supporting elements, and the form and hidden input elements of the email clients.
+// The other elements and the text are moved before the table by the html parser
+var ignoredElements = map[string]bool{"script": true, "template": true, "style": true, "form": true, "input": true}
+
+// structureChildren return the children elements of a table, row group or row element, only the elements
+// named in the list when it is not empty. In the lenient mode, the ignored elements are removed
+func structureChildren(element *goquery.Selection, names ...string) *goquery.Selection {
+	var children = element.Children()
+	if len(names) != 0 {
+		children = element.ChildrenFiltered(strings.Join(names, ", "))
+	}
+	if options.Lenient == false {
+		return children
+	}
+	return children.FilterFunction(func(index int, child *goquery.Selection) bool {
+		return ignoredElements[goquery.NodeName(child)] == false
+	})
+}
+
+// reportIgnoredChildren add an info note for each child of the table, row group or row element
+// ignored by the lenient mode
+func reportIgnoredChildren(element *goquery.Selection) {
+	if options.Lenient == false {
+		return
+	}
+
+	element.Children().Each(func(index int, child *goquery.Selection) {
+		if ignoredElements[goquery.NodeName(child)] == true {
+			addDiagnosticArgs(43, child, map[string]string{"element": goquery.NodeName(child)})
+		}
+	})
+}
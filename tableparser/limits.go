@@ -0,0 +1,126 @@
+package tableparser
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Default limits of the table size, see Options
+const (
+	DefaultMaxRows    = 10000
+	DefaultMaxColumns = 1000
+	DefaultMaxSlots   = 1000000
+)
+
+// The span values are capped when they are counted, the product of two spans can not overflow
+const maxCountedSpan = 1 << 30
+
+// parseContext is the context of the table being parsed, it is checked before each row
+var parseContext = context.Background()
+
+// limitExceeded is set when the table is too large to be parsed
+var limitExceeded = false
+
+// checkLimits count the rows, the columns and the slots of the table before the parsing,
+// the parser allocate the spanned slots so a too large table is not parsed
+func checkLimits(table *goquery.Selection) error {
+	var rows = 0
+	var slots = 0
+	var err error
+
+	// The columns defined by the colgroup and col elements
+	var colgroupColumns = 0
+	structureChildren(table, "colgroup").Each(func(index int, colgroup *goquery.Selection) {
+		var cols = colgroup.ChildrenFiltered("col")
+		if cols.Length() == 0 {
+			colgroupColumns += countedSpan(colgroup, "span")
+			return
+		}
+		cols.Each(func(index int, col *goquery.Selection) {
+			colgroupColumns += countedSpan(col, "span")
+		})
+	})
+	if options.MaxColumns > 0 && colgroupColumns > options.MaxColumns {
+		return limitDiagnostic(41, table, colgroupColumns, options.MaxColumns)
+	}
+
+	structureChildren(table, "thead", "tbody", "tfoot", "tr").EachWithBreak(func(index int, group *goquery.Selection) bool {
+		var groupRows = group
+		if goquery.NodeName(group) != "tr" {
+			groupRows = structureChildren(group, "tr")
+		}
+
+		groupRows.EachWithBreak(func(rowIndex int, row *goquery.Selection) bool {
+			rows++
+			if options.MaxRows > 0 && rows > options.MaxRows {
+				err = limitDiagnostic(40, row, rows, options.MaxRows)
+				return false
+			}
+
+			var rowColumns = 0
+			structureChildren(row, "th", "td").EachWithBreak(func(cellIndex int, cell *goquery.Selection) bool {
+				var width = countedSpan(cell, "colspan")
+				var height = countedSpan(cell, "rowspan")
+				rowColumns += width
+				if options.MaxColumns > 0 && rowColumns > options.MaxColumns {
+					err = limitDiagnostic(41, cell, rowColumns, options.MaxColumns)
+					return false
+				}
+
+				slots += width * height
+				if options.MaxSlots > 0 && slots > options.MaxSlots {
+					err = limitDiagnostic(42, cell, slots, options.MaxSlots)
+					return false
+				}
+				return true
+			})
+			return err == nil
+		})
+		return err == nil
+	})
+	return err
+}
+
+// checkRowLimits count the columns of a row streamed by StreamReader. The streamed rows are not kept
+// in memory, they are not counted in the rows and the slots limits
+func checkRowLimits(row *goquery.Selection) error {
+	var rowColumns = 0
+	var err error
+	structureChildren(row, "th", "td").EachWithBreak(func(index int, cell *goquery.Selection) bool {
+		rowColumns += countedSpan(cell, "colspan")
+		if options.MaxColumns > 0 && rowColumns > options.MaxColumns {
+			err = limitDiagnostic(41, cell, rowColumns, options.MaxColumns)
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// countedSpan return the span attribute like the parser read it, at least 1 and capped
+func countedSpan(elem *goquery.Selection, name string) int {
+	var spanVal, exists = elem.Attr(name)
+	if exists == false {
+		return 1
+	}
+	// Like the parser, a too large value is read as the largest int
+	var span, _ = strconv.Atoi(spanVal)
+	if span < 1 {
+		return 1
+	}
+	if span > maxCountedSpan {
+		return maxCountedSpan
+	}
+	return span
+}
+
+// limitDiagnostic create the diagnostic of an exceeded limit, the table is not parsed
+func limitDiagnostic(code int, elem *goquery.Selection, value int, limit int) error {
+	limitExceeded = true
+	return newDiagnosticArgs(code, elem, map[string]string{
+		"value": strconv.Itoa(value),
+		"limit": strconv.Itoa(limit),
+	})
+}
@@ -0,0 +1,147 @@
+package tableparser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// HassumMode define how the summary row group and summary colgroup are detected
+type HassumMode int
+
+const (
+	// HassumClass turn on the summary group detection when the table has the "hassum" class (WET-BOEW convention)
+	HassumClass HassumMode = iota
+	// HassumOn always turn on the summary group detection
+	HassumOn
+	// HassumOff always turn off the summary group detection
+	HassumOff
+	// HassumAuto turn on the summary group detection when a tbody without header row
+	// have a "Total" or "Subtotal" row header
+	HassumAuto
+)
+
+// HassumAttribute is the data attribute used to set the hassum mode per table,
+// the value can be "on", "off" or "auto"
+const HassumAttribute = "data-hassum"
+
+// Options are used to configure the table parser
+type Options struct {
+	Hassum HassumMode
+	// FixDescribedBy link the header cells with their description and key cells
+	// by setting the aria-describedby attribute, an id is generated when needed
+	FixDescribedBy bool
+	// IgnoreLayoutCell turn off the layout cell rules (code 17, 38 and 39),
+	// for the tables that put a label in the top left corner cell on purpose
+	IgnoreLayoutCell bool
+	// EnabledRules are the codes of the rules to report, all the rules are reported when it is empty
+	EnabledRules []int
+	// DisabledRules are the codes of the rules that are not reported.
+	// The parser still stop on a disabled rule when it can not process the rest of the table
+	DisabledRules []int
+	// Severities override the default severity of the rules, by code
+	Severities map[int]Severity
+	// Suppressions are the rules suppressed by table fingerprint, see Fingerprint.
+	// The rules are listed by code or id, an empty list suppress all the rules
+	Suppressions map[string][]string
+	// Lang is the language of the messages, like "en" or "fr", see RegisterCatalog
+	Lang string
+	// Catalog replace the messages of the language, by message key
+	Catalog Catalog
+	// MaxRows, MaxColumns and MaxSlots limit the size of the parsed table, a table that
+	// exceed a limit is not parsed. The slots are the cells multiplied by their spans, 0 is no limit
+	MaxRows    int
+	MaxColumns int
+	MaxSlots   int
+	// Lenient ignore the script-supporting elements and the form and hidden input elements in the table structure,
+	// the other elements and the text are moved out of the table by the html parser. They are reported as info notes (code 43)
+	Lenient bool
+}
+
+// DefaultOptions return the options used by Init
+func DefaultOptions() Options {
+	return Options{
+		Hassum: HassumClass,
+		Lang:   DefaultLang,
+
+		MaxRows:    DefaultMaxRows,
+		MaxColumns: DefaultMaxColumns,
+		MaxSlots:   DefaultMaxSlots,
+	}
+}
+
+var options = DefaultOptions()
+
+var totalHeaderRegexp = regexp.MustCompile(`(?i)^\s*(sub|sous)?[\s-]?totals?\b`)
+
+// ParseHassumMode return the hassum mode from his name, ok is false when the name is unknown
+func ParseHassumMode(name string) (mode HassumMode, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "class", "":
+		return HassumClass, true
+	case "on", "true", "yes":
+		return HassumOn, true
+	case "off", "false", "no":
+		return HassumOff, true
+	case "auto":
+		return HassumAuto, true
+	}
+	return HassumClass, false
+}
+
+// isHassumMode check if the summary group detection is turned on for the table
+func isHassumMode(table *goquery.Selection, mode HassumMode) bool {
+	// The data attribute on the table have priority on the parser option
+	if attrVal, exists := table.Attr(HassumAttribute); exists == true {
+		if attrMode, ok := ParseHassumMode(attrVal); ok == true && attrMode != HassumClass {
+			mode = attrMode
+		}
+	}
+
+	switch mode {
+	case HassumOn:
+		return true
+	case HassumOff:
+		return false
+	case HassumAuto:
+		return table.HasClass("hassum") || detectSummaryRowGroup(table)
+	}
+	return table.HasClass("hassum")
+}
+
+// detectSummaryRowGroup check for a tbody without header row that have a "Total" or "Subtotal" row header
+func detectSummaryRowGroup(table *goquery.Selection) bool {
+	var found = false
+	structureChildren(table, "tbody").EachWithBreak(func(index int, tbody *goquery.Selection) bool {
+		var rows = structureChildren(tbody, "tr")
+		var hasHeaderRow = false
+		var hasTotalRow = false
+
+		rows.Each(func(idx int, row *goquery.Selection) {
+			var cells = structureChildren(row, "th", "td")
+			if cells.Length() == 0 {
+				return
+			}
+
+			// A row with only header cells are used to label the row group
+			if cells.Length() == cells.Filter("th").Length() {
+				hasHeaderRow = true
+				return
+			}
+
+			var firstCell = cells.First()
+			if goquery.NodeName(firstCell) == "th" && totalHeaderRegexp.MatchString(firstCell.Text()) {
+				hasTotalRow = true
+			}
+		})
+
+		if hasHeaderRow == false && hasTotalRow == true {
+			found = true
+			return false
+		}
+		return true
+	})
+
+	return found
+}
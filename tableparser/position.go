@@ -0,0 +1,279 @@
+package tableparser
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Position is a position in the html source, the offset and the column are in bytes, the line and the column start at 1
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// SourceMap find the source positions of the elements of a document parsed from the same source.
+// The html parser does not keep the positions, so each start tag of the source is marked with his number
+// and the marked source is parsed again, the two documents are walked together to find the tag of each element.
+// The implied elements, like a tbody without tag, do not have a position
+type SourceMap struct {
+	source     string
+	lineStarts []int
+	elements   map[*html.Node]sourceElement
+}
+
+// sourceElement are the offsets of the tags of an element, the end offsets are -1 without end tag
+type sourceElement struct {
+	tagStart    int
+	tagEnd      int
+	endTagStart int
+	endTagEnd   int
+}
+
+// The attribute that mark the start tags with their number, see NewSourceMap
+const sourceTagAttribute = "data-tablevalidator-tag"
+
+// The elements without end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// NewSourceMap read the start and end tags of the source and match them with the elements of the document
+func NewSourceMap(source string, root *html.Node) *SourceMap {
+	var sourceMap = &SourceMap{
+		source:     source,
+		lineStarts: []int{0},
+		elements:   map[*html.Node]sourceElement{},
+	}
+	for i := 0; i < len(source); i++ {
+		if source[i] == '\n' {
+			sourceMap.lineStarts = append(sourceMap.lineStarts, i+1)
+		}
+	}
+
+	// The tags of the source in the source order, the source is copied with the start tags marked by their number
+	var tags = []*sourceElement{}
+	var tagNames = []string{}
+	var marked = strings.Builder{}
+	var stack = []*sourceElement{}
+	var stackNames = []string{}
+	var tokenizer = html.NewTokenizer(strings.NewReader(source))
+	var offset = 0
+	for {
+		var tokenType = tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		var raw = tokenizer.Raw()
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			var name, _ = tokenizer.TagName()
+			var elem = &sourceElement{tagStart: offset, tagEnd: offset + len(raw), endTagStart: -1, endTagEnd: -1}
+			if tokenType == html.StartTagToken && voidElements[string(name)] == false {
+				stack = append(stack, elem)
+				stackNames = append(stackNames, string(name))
+			}
+
+			// The mark is the first attribute, after the tag name
+			var nameEnd = 1
+			for nameEnd < len(raw) && strings.IndexByte(" \t\n\f\r/>", raw[nameEnd]) < 0 {
+				nameEnd++
+			}
+			marked.Write(raw[:nameEnd])
+			marked.WriteString(" " + sourceTagAttribute + "=\"" + strconv.Itoa(len(tags)) + "\"")
+			marked.Write(raw[nameEnd:])
+			tags = append(tags, elem)
+			tagNames = append(tagNames, string(name))
+			offset += len(raw)
+			continue
+		case html.EndTagToken:
+			var name, _ = tokenizer.TagName()
+			// The elements opened after the matching one are closed without end tag
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stackNames[i] == string(name) {
+					stack[i].endTagStart = offset
+					stack[i].endTagEnd = offset + len(raw)
+					stack = stack[:i]
+					stackNames = stackNames[:i]
+					break
+				}
+			}
+		}
+		marked.Write(raw)
+		offset += len(raw)
+	}
+
+	// The marked source give the same document, with the number of the tag on each element
+	markedRoot, err := html.Parse(strings.NewReader(marked.String()))
+	if err == nil && matchSourceTags(root, markedRoot, tags, sourceMap.elements) == true {
+		return sourceMap
+	}
+
+	// The document is not parsed from the same source, the tags are matched by name only when
+	// the numbers are the same, otherwise an implied element could shift them
+	sourceMap.elements = map[*html.Node]sourceElement{}
+	var tagsByName = map[string][]*sourceElement{}
+	for i, elem := range tags {
+		tagsByName[tagNames[i]] = append(tagsByName[tagNames[i]], elem)
+	}
+	var nodes = map[string][]*html.Node{}
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode {
+			nodes[node.Data] = append(nodes[node.Data], node)
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(root)
+	for name, elems := range nodes {
+		if len(tagsByName[name]) != len(elems) {
+			continue
+		}
+		for i, node := range elems {
+			sourceMap.elements[node] = *tagsByName[name][i]
+		}
+	}
+	return sourceMap
+}
+
+// matchSourceTags walk the document and the document of the marked source together and keep the tag of each element,
+// it return false when the two documents are not the same
+func matchSourceTags(node *html.Node, marked *html.Node, tags []*sourceElement, elements map[*html.Node]sourceElement) bool {
+	if node.Type != marked.Type || (node.Type == html.ElementNode && node.Data != marked.Data) {
+		return false
+	}
+	if node.Type == html.ElementNode {
+		for _, attr := range marked.Attr {
+			if attr.Key != sourceTagAttribute {
+				continue
+			}
+			if index, err := strconv.Atoi(attr.Val); err == nil && index >= 0 && index < len(tags) {
+				elements[node] = *tags[index]
+			}
+		}
+	}
+
+	var child, markedChild = node.FirstChild, marked.FirstChild
+	for child != nil && markedChild != nil {
+		if matchSourceTags(child, markedChild, tags, elements) == false {
+			return false
+		}
+		child, markedChild = child.NextSibling, markedChild.NextSibling
+	}
+	return child == nil && markedChild == nil
+}
+
+// NewSourceMapReader read the source and parse it, it return the source map and the parsed document
+func NewSourceMapReader(r io.Reader) (*SourceMap, *html.Node, error) {
+	var builder = strings.Builder{}
+	if _, err := io.Copy(&builder, r); err != nil {
+		return nil, nil, err
+	}
+	var source = builder.String()
+
+	root, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewSourceMap(source, root), root, nil
+}
+
+// Source return the html source
+func (m *SourceMap) Source() string {
+	return m.source
+}
+
+// Position return the line and the column of an offset
+func (m *SourceMap) Position(offset int) Position {
+	var line = sort.Search(len(m.lineStarts), func(i int) bool {
+		return m.lineStarts[i] > offset
+	})
+	return Position{
+		Offset: offset,
+		Line:   line,
+		Column: offset - m.lineStarts[line-1] + 1,
+	}
+}
+
+// StartTag return the positions of the start tag of the element, ok is false when the element has no tag in the source
+func (m *SourceMap) StartTag(sel *goquery.Selection) (start Position, end Position, ok bool) {
+	if sel == nil || sel.Length() == 0 {
+		return Position{}, Position{}, false
+	}
+	var elem, exists = m.elements[sel.Nodes[0]]
+	if exists == false {
+		return Position{}, Position{}, false
+	}
+	return m.Position(elem.tagStart), m.Position(elem.tagEnd), true
+}
+
+// NearestStartTag return the positions of the start tag of the element. When the element has no tag in the source,
+// the position of the first descendant with a tag is used, then the position of the closest parent
+func (m *SourceMap) NearestStartTag(sel *goquery.Selection) (start Position, end Position, ok bool) {
+	var elem, found = m.find(sel)
+	if found == false {
+		return Position{}, Position{}, false
+	}
+	return m.Position(elem.tagStart), m.Position(elem.tagEnd), true
+}
+
+// Element return the positions of the element from his start tag to the end of his end tag,
+// ok is false when the element does not have both tags in the source
+func (m *SourceMap) Element(sel *goquery.Selection) (start Position, end Position, ok bool) {
+	if sel == nil || sel.Length() == 0 {
+		return Position{}, Position{}, false
+	}
+	var elem, exists = m.elements[sel.Nodes[0]]
+	if exists == false || elem.endTagEnd < 0 {
+		return Position{}, Position{}, false
+	}
+	return m.Position(elem.tagStart), m.Position(elem.endTagEnd), true
+}
+
+// EndTag return the positions of the end tag of the element, ok is false without end tag
+func (m *SourceMap) EndTag(sel *goquery.Selection) (start Position, end Position, ok bool) {
+	if sel == nil || sel.Length() == 0 {
+		return Position{}, Position{}, false
+	}
+	var elem, exists = m.elements[sel.Nodes[0]]
+	if exists == false || elem.endTagEnd < 0 {
+		return Position{}, Position{}, false
+	}
+	return m.Position(elem.endTagStart), m.Position(elem.endTagEnd), true
+}
+
+// find return the source tags of the element, of his first descendant or of his closest parent
+func (m *SourceMap) find(sel *goquery.Selection) (sourceElement, bool) {
+	if sel == nil || sel.Length() == 0 {
+		return sourceElement{}, false
+	}
+	if elem, exists := m.elements[sel.Nodes[0]]; exists == true {
+		return elem, true
+	}
+
+	var found = sourceElement{}
+	var exists = false
+	sel.First().Find("*").EachWithBreak(func(index int, child *goquery.Selection) bool {
+		found, exists = m.elements[child.Nodes[0]]
+		return exists == false
+	})
+	if exists == true {
+		return found, true
+	}
+
+	for node := sel.Nodes[0].Parent; node != nil; node = node.Parent {
+		if elem, exists := m.elements[node]; exists == true {
+			return elem, true
+		}
+	}
+	return sourceElement{}, false
+}
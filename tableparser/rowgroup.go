@@ -0,0 +1,77 @@
+package tableparser
+
+import (
+	"github.com/PuerkitoBio/goquery"
+)
+
+// RowGroupNode is a row group in the row hierarchy of the table
+type RowGroupNode struct {
+	// Type is either TypeData or TypeSummary
+	Type Type
+	// Level of the row group, the tfoot summary group are at level 0
+	Level int
+	// Labels are the group header cells of the row group, from the generic to the specific
+	Labels []Cell
+	// Selection is the tbody or tfoot element, it is nil for a virtual row group
+	Selection *goquery.Selection
+	// Rows are the tr elements of the row group
+	Rows []*goquery.Selection
+	// Parent is the data row group that contains this row group, nil at the top level
+	Parent   *RowGroupNode
+	Children []*RowGroupNode
+	// Summary is the summary row group that totals this row group, if any
+	Summary *RowGroupNode
+}
+
+// Virtual return true when the row group was not defined by a tbody or tfoot element
+func (n *RowGroupNode) Virtual() bool {
+	return n.Selection == nil
+}
+
+// buildRowGroupTree convert the row group list into the row hierarchy
+func buildRowGroupTree(rowgroups []RowGroup) []*RowGroupNode {
+	var nodes = []*RowGroupNode{}
+	var dataStack = []*RowGroupNode{}
+
+	for _, rowgroup := range rowgroups {
+		var node = &RowGroupNode{
+			Type:      Type(rowgroup.etype),
+			Level:     rowgroup.level,
+			Labels:    rowgroup.headerlevel,
+			Selection: rowgroup.elem,
+			Rows:      []*goquery.Selection{},
+			Children:  []*RowGroupNode{},
+		}
+		for _, row := range rowgroup.row {
+			node.Rows = append(node.Rows, row.elem)
+		}
+
+		// The parent is the closest data row group at a lower level
+		for len(dataStack) > 0 && dataStack[len(dataStack)-1].Level >= node.Level {
+			dataStack = dataStack[:len(dataStack)-1]
+		}
+		if len(dataStack) > 0 {
+			node.Parent = dataStack[len(dataStack)-1]
+			node.Parent.Children = append(node.Parent.Children, node)
+		}
+
+		if node.Type == TypeSummary {
+			// Attach the summary to the previous row groups that it totals
+			for i := len(nodes) - 1; i >= 0; i-- {
+				var prev = nodes[i]
+				if prev.Level < node.Level || (prev.Type == TypeSummary && prev.Level == node.Level) {
+					break
+				}
+				if prev.Summary == nil {
+					prev.Summary = node
+				}
+			}
+		} else {
+			dataStack = append(dataStack, node)
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
@@ -0,0 +1,419 @@
+package tableparser
+
+// Rule is a validation rule of the table parser
+type Rule struct {
+	// ID is the stable name of the rule
+	ID string
+	// Code is the stable number of the rule, it is the number used by the WET-BOEW table parser
+	Code int
+	// Severity is the default severity of the rule
+	Severity Severity
+	// Message is the short message of the diagnostic
+	Message string
+	// Description explain the rule
+	Description string
+	// Reference is the specification section of the rule
+	Reference string
+	// Fix is an hint to fix the problem
+	Fix string
+	// Criteria are the WCAG success criteria of the rule, see CriterionByID
+	Criteria []string
+	// Techniques are the WCAG techniques and failures of the rule, see TechniqueByID
+	Techniques []string
+}
+
+const htmlTablesSpec = "https://html.spec.whatwg.org/multipage/tables.html"
+
+var rules = []Rule{
+	{
+		ID:          "colgroup-header-span",
+		Code:        3,
+		Severity:    SeverityWarning,
+		Message:     "The first colgroup must be spanned to represent the header column group",
+		Description: "When the table have row header cells and colgroup elements, the first colgroup represent the header columns. It must span exactly the columns used by the row header cells, and the colgroups must cover the width of the table.",
+		Reference:   htmlTablesSpec + "#the-colgroup-element",
+		Fix:         "Set the span of the first colgroup to the number of row header columns, and make the colgroups cover all the columns.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "description-row-cell",
+		Code:        4,
+		Severity:    SeverityWarning,
+		Message:     "You have an invalid cell inside a row description",
+		Description: "A description row in the thead only contains the description cells of the header cells above them, or a layout cell.",
+		Reference:   htmlTablesSpec + "#the-thead-element",
+		Fix:         "Use one td element per header cell in the description row, with the same width and without rowspan.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "data-colgroup-missing",
+		Code:        5,
+		Severity:    SeverityWarning,
+		Message:     "You need at least one data colgroup, review your table structure",
+		Description: "The row header cells use all the columns of the table, there is no column left for the data cells.",
+		Reference:   htmlTablesSpec + "#header-and-data-cell-semantics",
+		Fix:         "Use td elements for the data cells, the th elements are only for the header cells.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51", "F91"},
+	},
+	{
+		ID:          "colgroup-lowest-level",
+		Code:        6,
+		Severity:    SeverityError,
+		Message:     "The Lowest column group level have been found, You may have an error in you column structure",
+		Description: "A colgroup that totals the whole table was already found, no other colgroup can follow it.",
+		Reference:   htmlTablesSpec + "#the-colgroup-element",
+		Fix:         "Move the colgroup that totals the whole table at the end, or review the header cells that define the colgroup levels.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "header-colgroup-width",
+		Code:        7,
+		Severity:    SeverityWarning,
+		Message:     "The initial colgroup should group all the header, there are no place for any data cell",
+		Description: "The first colgroup represent the header columns, it must end with the last column used by the row header cells.",
+		Reference:   htmlTablesSpec + "#the-colgroup-element",
+		Fix:         "Set the span of the first colgroup to the number of row header columns.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51", "F91"},
+	},
+	{
+		ID:          "header-cell-crossing-colgroup",
+		Code:        9,
+		Severity:    SeverityError,
+		Message:     "Error in you header row group, there are cell that are crossing more than one colgroup",
+		Description: "A column header cell below the level of a colgroup can not span across two colgroups.",
+		Reference:   htmlTablesSpec + "#the-colgroup-element",
+		Fix:         "Reduce the colspan of the header cell, or change the colgroup elements to match the header cells.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51", "F91"},
+	},
+	{
+		ID:          "group-header-cell-encapsulate",
+		Code:        10,
+		Severity:    SeverityError,
+		Message:     "The header group cell used to represent the data at level must encapsulate his group",
+		Description: "A column header cell above a colgroup is a group header cell, it must span all the columns of the colgroups it represent.",
+		Reference:   htmlTablesSpec + "#the-colgroup-element",
+		Fix:         "Set the colspan of the group header cell to cover all the columns of his colgroups.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51", "F91"},
+	},
+	{
+		ID:          "summary-rowgroup-last",
+		Code:        12,
+		Severity:    SeverityWarning,
+		Message:     "Last summary row group already found",
+		Description: "Each summary row group totals a lower level, the summary row group of the whole table was already found.",
+		Reference:   htmlTablesSpec + "#the-tbody-element",
+		Fix:         "Remove the extra summary row group, or add a header row to the tbody to make it a data row group.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "rowgroup-not-calculated",
+		Code:        13,
+		Severity:    SeverityWarning,
+		Message:     "Error, Row group not calculated",
+		Description: "The type of the row group can not be found, it is neither a data row group nor a summary row group.",
+		Reference:   htmlTablesSpec + "#the-tbody-element",
+		Fix:         "Review the header rows and the row header cells of the row group.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "rowgroup-level",
+		Code:        14,
+		Severity:    SeverityWarning,
+		Message:     "The row group level can not be calculated",
+		Description: "The level of the row group is calculated from the previous row groups, a summary row group can not go below the level of the whole table.",
+		Reference:   htmlTablesSpec + "#the-tbody-element",
+		Fix:         "Review the order of the data row groups and the summary row groups.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "row-child",
+		Code:        15,
+		Severity:    SeverityWarning,
+		Message:     "tr element need to only have th or td element as his child",
+		Description: "The content model of the tr element only allow th and td elements, and script-supporting elements.",
+		Reference:   htmlTablesSpec + "#the-tr-element",
+		Fix:         "Move the content inside a th or td element.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "row-width",
+		Code:        16,
+		Severity:    SeverityWarning,
+		Message:     "The row do not have a good width",
+		Description: "All the rows of the table must have the same number of columns, including the cells spanned from the previous rows.",
+		Reference:   htmlTablesSpec + "#forming-a-table",
+		Fix:         "Add the missing cells or review the colspan and rowspan attributes.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "layout-cell-not-empty",
+		Code:        17,
+		Severity:    SeverityWarning,
+		Message:     "The layout cell is not empty",
+		Description: "When the first row is the column header row, the top left corner cell is a layout cell and it must be empty.",
+		Reference:   htmlTablesSpec + "#header-and-data-cell-semantics",
+		Fix:         "Remove the content of the top left corner cell, or turn off the layout cell rules when the corner is a label on purpose.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51", "F46"},
+	},
+	{
+		ID:          "rowgroup-header-structure",
+		Code:        18,
+		Severity:    SeverityWarning,
+		Message:     "Row group header not well structured",
+		Description: "A header row of a row group only have one header cell that span all the columns, and the column header rows are only at the top of the table.",
+		Reference:   htmlTablesSpec + "#the-tbody-element",
+		Fix:         "Move the column header rows in the thead, and use one th element spanning all the columns for the row group header.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51", "F91"},
+	},
+	{
+		ID:          "header-row-in-tbody",
+		Code:        21,
+		Severity:    SeverityWarning,
+		Message:     "Move the row used as the column cell heading in the thead row group",
+		Description: "A row with column header cells was found after the first row of the table.",
+		Reference:   htmlTablesSpec + "#the-thead-element",
+		Fix:         "Move the row inside the thead element.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51", "F91"},
+	},
+	{
+		ID:          "parallel-row-headers",
+		Code:        23,
+		Severity:    SeverityWarning,
+		Message:     "Avoid the use of have paralel row headers, it's recommended do a cell merge to fix it",
+		Description: "Two row header cells with the same height in the same row can not be ordered from the generic to the specific.",
+		Reference:   htmlTablesSpec + "#header-and-data-cell-semantics",
+		Fix:         "Merge the row header cells, or use the rowspan to show which header is the most generic.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H43", "H63", "F90"},
+	},
+	{
+		ID:          "row-header-hierarchy",
+		Code:        24,
+		Severity:    SeverityWarning,
+		Message:     "For a data row, the heading hiearchy need to be the Generic to the specific",
+		Description: "The row header cells of a data row go from the generic (the tallest) to the specific (the shortest).",
+		Reference:   htmlTablesSpec + "#header-and-data-cell-semantics",
+		Fix:         "Order the row header cells from the generic to the specific.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H43", "H63", "F90"},
+	},
+	{
+		ID:          "problematic-key-cell",
+		Code:        25,
+		Severity:    SeverityWarning,
+		Message:     "You have a problematic key cell",
+		Description: "A data cell in the row header columns must be a description cell or a key cell of a row header cell with the same height.",
+		Reference:   htmlTablesSpec + "#header-and-data-cell-semantics",
+		Fix:         "Use a th element for the cell, or move it in the data columns.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H43", "F90"},
+	},
+	{
+		ID:          "row-before-thead",
+		Code:        26,
+		Severity:    SeverityWarning,
+		Message:     "You can not define any row before the thead group",
+		Description: "The thead element is the first row group of the table.",
+		Reference:   htmlTablesSpec + "#the-thead-element",
+		Fix:         "Move the thead element before the rows.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "rowgroup-child",
+		Code:        27,
+		Severity:    SeverityWarning,
+		Message:     "thead, tbody and tfoot element need to only have tr element as his child",
+		Description: "The content model of the thead, tbody and tfoot elements only allow tr elements, and script-supporting elements.",
+		Reference:   htmlTablesSpec + "#the-tbody-element",
+		Fix:         "Move the content inside a cell of a tr element.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "rowspan-across-rowgroup",
+		Code:        29,
+		Severity:    SeverityWarning,
+		Message:     "You cannot span cell in 2 different rowgroup",
+		Description: "A cell can not span rows of two row groups, including the virtual row groups made by the summary rows.",
+		Reference:   htmlTablesSpec + "#forming-a-table",
+		Fix:         "Reduce the rowspan of the cell to the last row of his row group.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "table-markup",
+		Code:        30,
+		Severity:    SeverityError,
+		Message:     "Use the appropriate table markup",
+		Description: "The table element only allow caption, colgroup, thead, tbody, tfoot and tr elements as child.",
+		Reference:   htmlTablesSpec + "#the-table-element",
+		Fix:         "Move the content inside the caption or inside a cell.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51", "F46"},
+	},
+	{
+		ID:          "virtual-column",
+		Code:        31,
+		Severity:    SeverityError,
+		Message:     "Internal Error, Number of virtual column must be set [func processColgroup()]",
+		Description: "Internal error of the parser when it create the virtual colgroup.",
+		Reference:   htmlTablesSpec + "#the-colgroup-element",
+		Fix:         "Report the table that cause this error.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "row-header-structure",
+		Code:        32,
+		Severity:    SeverityWarning,
+		Message:     "Check your row cell headers structure",
+		Description: "The number of row header columns changed in a way that can not be a virtual summary or data row group.",
+		Reference:   htmlTablesSpec + "#header-and-data-cell-semantics",
+		Fix:         "Use the same number of row header columns for the rows of a row group.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H43", "H63", "F90"},
+	},
+	{
+		ID:          "data-rowgroup-mark",
+		Code:        34,
+		Severity:    SeverityWarning,
+		Message:     "Mark properly your data row group",
+		Description: "A data row group was found after a virtual summary row group without being marked with a tbody element.",
+		Reference:   htmlTablesSpec + "#the-tbody-element",
+		Fix:         "Put the data rows in their own tbody element.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "col-definition",
+		Code:        35,
+		Severity:    SeverityWarning,
+		Message:     "Column, col element, are not correctly defined",
+		Description: "The col elements do not match the columns of the table.",
+		Reference:   htmlTablesSpec + "#the-col-element",
+		Fix:         "Review the span attribute of the col elements.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "multiple-tfoot",
+		Code:        36,
+		Severity:    SeverityWarning,
+		Message:     "A table can only have one tfoot element",
+		Description: "The tfoot element is the summary of the whole table, there is only one per table.",
+		Reference:   htmlTablesSpec + "#the-tfoot-element",
+		Fix:         "Merge the rows of the tfoot elements, or use a tbody element for the summary of a part of the table.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "tfoot-row-width",
+		Code:        37,
+		Severity:    SeverityWarning,
+		Message:     "The tfoot row do not have the same width as the table body",
+		Description: "The rows of the tfoot summarize the columns of the table body, they must have the same number of columns.",
+		Reference:   htmlTablesSpec + "#the-tfoot-element",
+		Fix:         "Add the missing cells or review the colspan attributes of the tfoot row.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51"},
+	},
+	{
+		ID:          "layout-cell-th",
+		Code:        38,
+		Severity:    SeverityWarning,
+		Message:     "The layout cell need to be a td element, not an empty th element",
+		Description: "An empty th element is an header cell without content, the top left corner cell is only for the layout.",
+		Reference:   htmlTablesSpec + "#the-th-element",
+		Fix:         "Replace the empty th element by an empty td element.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51", "F91"},
+	},
+	{
+		ID:          "layout-cell-span",
+		Code:        39,
+		Severity:    SeverityWarning,
+		Message:     "The layout cell can not span into the data columns",
+		Description: "The top left corner layout cell is above the row header columns, the data columns need a column header cell.",
+		Reference:   htmlTablesSpec + "#header-and-data-cell-semantics",
+		Fix:         "Reduce the colspan of the layout cell and add the missing column header cells.",
+		Criteria:    []string{"1.3.1"},
+		Techniques:  []string{"H51", "F91"},
+	},
+	{
+		ID:          "max-rows",
+		Code:        40,
+		Severity:    SeverityError,
+		Message:     "The table have too many rows to be validated",
+		Description: "The table have more rows than the MaxRows option, it is not parsed to limit the memory used by the parser.",
+		Reference:   htmlTablesSpec + "#the-tr-element",
+		Fix:         "Split the table, or raise the MaxRows limit when the table is expected to be that large.",
+	},
+	{
+		ID:          "max-columns",
+		Code:        41,
+		Severity:    SeverityError,
+		Message:     "The table have too many columns to be validated",
+		Description: "A row or the colgroups span more columns than the MaxColumns option, the table is not parsed to limit the memory used by the parser. A large colspan value is often a typo.",
+		Reference:   htmlTablesSpec + "#attr-tdth-colspan",
+		Fix:         "Check the colspan and span values, or raise the MaxColumns limit.",
+	},
+	{
+		ID:          "max-slots",
+		Code:        42,
+		Severity:    SeverityError,
+		Message:     "The table have too many spanned slots to be validated",
+		Description: "The cells multiplied by their colspan and rowspan cover more slots than the MaxSlots option, the table is not parsed to limit the memory used by the parser.",
+		Reference:   htmlTablesSpec + "#attr-tdth-rowspan",
+		Fix:         "Check the colspan and rowspan values, or raise the MaxSlots limit.",
+	},
+	{
+		ID:          "ignored-markup",
+		Code:        43,
+		Severity:    SeverityInfo,
+		Message:     "This markup is ignored in the table structure",
+		Description: "In the lenient mode, the script-supporting elements and the form and hidden input elements in the table structure are ignored. This markup is often added by the templates and the email clients.",
+		Reference:   htmlTablesSpec + "#the-tr-element",
+		Fix:         "Remove the markup from the table structure, or move it inside a cell.",
+	},
+}
+
+// Rules return the rules of the table parser
+func Rules() []Rule {
+	return append([]Rule{}, rules...)
+}
+
+// RuleByCode return the rule with the code, ok is false when the code is unknown
+func RuleByCode(code int) (rule Rule, ok bool) {
+	for _, rule := range rules {
+		if rule.Code == code {
+			return rule, true
+		}
+	}
+	return Rule{Code: code, Severity: SeverityWarning}, false
+}
+
+// RuleByID return the rule with the id, ok is false when the id is unknown
+func RuleByID(id string) (rule Rule, ok bool) {
+	for _, rule := range rules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
@@ -0,0 +1,500 @@
+package tableparser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// streamedRows is set while StreamReader parse a table, the rows are validated one at a time and they are not kept
+var streamedRows = false
+
+// StreamReader read the html document with the tokenizer and parse the tables one at a time,
+// fn is called with the result of each table as soon as the table is parsed.
+// The rows of the tbody, and the rows directly in the table, are validated as soon as they are read
+// and they are dropped after, so the memory used does not grow with the number of rows. The caption,
+// the colgroups, the thead and the tfoot are kept with the table, the tfoot is validated at the end of the table.
+// Because the rows are dropped:
+//   - the table does not have a grid, and the row groups and the columns do not list their rows and cells
+//   - the MaxRows and MaxSlots limits only count the rows of the thead and of the tfoot, MaxColumns is checked on each row
+//   - HassumAuto does not look for the summary row groups in the rows, only the class and the data-hassum attribute are read
+//
+// The nested tables are parsed after their outer table, each one whole. The parser is locked while a table
+// is read, the stream stop on the first error of fn. fn is called without the lock of the parser, it can parse other tables
+func StreamReader(ctx context.Context, r io.Reader, opts Options, fn func(Result) error) error {
+	var tokenizer = html.NewTokenizer(r)
+	var index = 0
+
+	// The tables with the same structure are numbered across the stream
+	var fingerprints = map[string]int{}
+
+	// The comments just before the table are kept for the tablevalidator-disable comment
+	var comments = bytes.Buffer{}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var tokenType = tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return nil
+			}
+			return tokenizer.Err()
+		case html.CommentToken:
+			comments.Write(tokenizer.Raw())
+			continue
+		case html.TextToken:
+			if len(bytes.TrimSpace(tokenizer.Raw())) == 0 {
+				comments.Write(tokenizer.Raw())
+				continue
+			}
+		case html.StartTagToken:
+			var name, _ = tokenizer.TagName()
+			if string(name) == "table" {
+				var stream = &tableStream{
+					tokenizer:    tokenizer,
+					opts:         opts,
+					fingerprints: fingerprints,
+					tfoot:        []*goquery.Selection{},
+					nested:       []*goquery.Selection{},
+				}
+				stream.head.Write(comments.Bytes())
+				stream.head.Write(tokenizer.Raw())
+
+				var results, err = stream.parse(ctx)
+				for _, result := range results {
+					index++
+					result.Index = index
+					if fnErr := fn(result); fnErr != nil {
+						return fnErr
+					}
+				}
+				if err != nil && err != io.EOF {
+					return err
+				}
+			}
+		}
+		comments.Reset()
+	}
+}
+
+// The parent of the tokens copied by the table stream
+const (
+	// The tokens are in the head of the table, before the first streamed row
+	streamHead = iota
+	// The tokens are a row, or an other element of a streamed row group
+	streamRowGroup
+	// The tokens are a tfoot
+	streamTfoot
+	// The tokens are an element of the table after the first streamed row, like a late caption
+	streamTable
+)
+
+// tableStream read a table with the tokenizer. The tokens are copied in chunks, a chunk is parsed
+// in the context of his parent element when the next chunk start
+type tableStream struct {
+	tokenizer    *html.Tokenizer
+	opts         Options
+	fingerprints map[string]int
+
+	// head are the comments before the table, the table start tag, the caption, the colgroups and the thead.
+	// They make the table element, it is parsed before the first streamed row
+	head     bytes.Buffer
+	table    *goquery.Selection
+	identity *identityWriter
+
+	// chunk are the tokens of the current element, target is his parent
+	chunk  bytes.Buffer
+	target int
+
+	// section is the row group element open in the source, and rowGroup is his element when it is streamed
+	section  string
+	rowGroup *goquery.Selection
+	inRow    bool
+
+	tfoot  []*goquery.Selection
+	nested []*goquery.Selection
+
+	// err stop the parser, the rows are still read until the end of the table for the fingerprint
+	err error
+}
+
+// parse read the tokens of the table until his end tag and return the result of the table and of his nested tables
+func (s *tableStream) parse(ctx context.Context) ([]Result, error) {
+	parserMutex.Lock()
+	defer parserMutex.Unlock()
+
+	parseContext = ctx
+	streamedRows = true
+	defer func() {
+		parseContext = context.Background()
+		streamedRows = false
+	}()
+
+	var readErr = s.read(ctx)
+	if readErr != nil && readErr != io.EOF {
+		return nil, readErr
+	}
+	s.finish()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var results = []Result{}
+	var table, err = tableResult(s.table, s.numbered(s.identity.sum()), s.err)
+	results = append(results, Result{Table: table, Err: err})
+
+	// The nested tables are in the rows, they are parsed whole
+	streamedRows = false
+	for _, nested := range s.nested {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		table, err := tableResult(nested, s.numbered(tableIdentity(nested)), parseTable(nested, s.opts))
+		if err == ctx.Err() && err != nil {
+			return results, err
+		}
+		results = append(results, Result{Table: table, Err: err})
+	}
+	return results, readErr
+}
+
+// read copy the tokens in the chunks until the end tag of the table, the chunks are parsed when they end
+func (s *tableStream) read(ctx context.Context) error {
+	var depth = 1
+	var template = 0
+	for {
+		var tokenType = s.tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return s.tokenizer.Err()
+		}
+
+		var name = ""
+		if tokenType == html.StartTagToken || tokenType == html.EndTagToken {
+			var tagName, _ = s.tokenizer.TagName()
+			name = string(tagName)
+		}
+
+		// The nested tables and the templates are copied with the element around them
+		if name == "table" && tokenType == html.StartTagToken {
+			depth++
+		} else if name == "table" && tokenType == html.EndTagToken {
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		} else if name == "template" && tokenType == html.StartTagToken {
+			template++
+		} else if name == "template" && tokenType == html.EndTagToken && template > 0 {
+			template--
+		}
+		if depth > 1 || template > 0 || name == "template" {
+			s.write(s.tokenizer.Raw())
+			continue
+		}
+
+		if tokenType == html.StartTagToken {
+			s.start(name)
+		} else if tokenType == html.EndTagToken {
+			s.end(name)
+		} else {
+			s.write(s.tokenizer.Raw())
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// start copy a start tag of the table, the rows and the row groups start a new chunk
+func (s *tableStream) start(name string) {
+	var inSection = s.section == "thead" || s.section == "tfoot"
+	switch {
+	case name == "tr" && inSection == false, (name == "td" || name == "th") && inSection == false && s.inRow == false:
+		// A cell without row start an implied row, and a row without row group start an implied tbody
+		s.flush()
+		if s.rowGroup == nil {
+			s.endSection()
+			s.beginRowGroup("<tbody>")
+		}
+		s.inRow = true
+		s.target = streamRowGroup
+	case name == "tbody":
+		s.flush()
+		s.endSection()
+		s.beginRowGroup(string(s.tokenizer.Raw()))
+		return
+	case name == "thead", name == "tfoot", name == "caption", name == "colgroup":
+		s.flush()
+		s.endSection()
+		s.section = name
+		s.target = streamTable
+		if name == "tfoot" {
+			s.buildTable()
+			s.target = streamTfoot
+		} else if s.table == nil {
+			s.target = streamHead
+		}
+		if name == "caption" || name == "colgroup" {
+			s.section = ""
+		}
+	}
+	s.write(s.tokenizer.Raw())
+}
+
+// end copy an end tag of the table, the end of a row or of a row group end the chunk
+func (s *tableStream) end(name string) {
+	if name == "tr" && s.inRow == true {
+		s.write(s.tokenizer.Raw())
+		s.flush()
+		s.inRow = false
+		return
+	}
+	if name != s.section {
+		s.write(s.tokenizer.Raw())
+		return
+	}
+
+	if name != "tbody" {
+		s.write(s.tokenizer.Raw())
+	}
+	s.flush()
+	s.endSection()
+}
+
+// write copy the token in the current chunk
+func (s *tableStream) write(token []byte) {
+	if s.target == streamHead {
+		s.head.Write(token)
+		return
+	}
+	s.chunk.Write(token)
+}
+
+// buildTable parse the head of the table, then his caption, colgroups and thead are processed
+func (s *tableStream) buildTable() {
+	if s.table != nil {
+		return
+	}
+	// The body start tag keep the comments before the table in the body, next to the table.
+	// The head start with the table start tag, the document always have the table
+	s.head.WriteString("</table>")
+	var doc, _ = goquery.NewDocumentFromReader(strings.NewReader("<body>" + s.head.String()))
+	s.head = bytes.Buffer{}
+
+	s.table = doc.Find("table").First()
+	s.identity = newIdentityWriter(s.table)
+	s.addRows(s.table.ChildrenFiltered("thead"))
+	s.addNested(s.table)
+
+	s.err = beginTable(s.table, s.opts)
+	if s.err != nil {
+		return
+	}
+	reportIgnoredChildren(s.table)
+	structureChildren(s.table).EachWithBreak(func(index int, element *goquery.Selection) bool {
+		s.err = processTableChild(element)
+		return s.err == nil
+	})
+}
+
+// beginRowGroup start a streamed tbody, tag is his start tag
+func (s *tableStream) beginRowGroup(tag string) {
+	s.buildTable()
+	s.section = "tbody"
+	s.target = streamRowGroup
+
+	var nodes, _ = html.ParseFragment(strings.NewReader(tag+"</tbody>"), s.table.Nodes[0])
+	for _, node := range nodes {
+		if node.Type == html.ElementNode && node.DataAtom == atom.Tbody {
+			s.table.Nodes[0].AppendChild(node)
+			s.rowGroup = s.table.FindNodes(node)
+		}
+	}
+	if s.err == nil {
+		s.err = beginRowGroup(s.rowGroup)
+	}
+}
+
+// endSection end the row group open in the source
+func (s *tableStream) endSection() {
+	if s.rowGroup != nil && s.err == nil {
+		s.err = endRowGroup()
+	}
+	s.rowGroup = nil
+	s.section = ""
+	s.inRow = false
+	s.target = streamTable
+	if s.table == nil {
+		s.target = streamHead
+	}
+}
+
+// flush parse the current chunk in the context of his parent and process his elements
+func (s *tableStream) flush() {
+	var chunk = s.chunk.String()
+	s.chunk.Reset()
+	if s.target == streamHead || len(strings.TrimSpace(chunk)) == 0 {
+		return
+	}
+
+	var parent = s.table.Nodes[0]
+	if s.target == streamRowGroup {
+		parent = s.rowGroup.Nodes[0]
+	}
+	var nodes, err = html.ParseFragment(strings.NewReader(chunk), parent)
+	if err != nil {
+		if s.err == nil {
+			s.err = err
+		}
+		return
+	}
+
+	for _, node := range nodes {
+		if node.Type != html.ElementNode {
+			continue
+		}
+		parent.AppendChild(node)
+		var element = s.table.FindNodes(node)
+		if s.target == streamRowGroup {
+			s.addRows(element)
+		} else {
+			s.addRows(element.Filter("thead, tfoot, tr"))
+		}
+		s.addNested(element)
+
+		switch {
+		case s.target == streamTfoot && node.DataAtom == atom.Tfoot:
+			// The tfoot are validated at the end of the table
+			s.tfoot = append(s.tfoot, element)
+		case s.target == streamTfoot:
+		case s.target == streamRowGroup:
+			s.process(element, true)
+			forgetRows(parent)
+		default:
+			s.process(element, false)
+		}
+	}
+}
+
+// process validate an element of the table, or of a row group when inRowGroup is set
+func (s *tableStream) process(element *goquery.Selection, inRowGroup bool) {
+	if s.err != nil {
+		return
+	}
+	var name = goquery.NodeName(element)
+	if options.Lenient == true && ignoredElements[name] == true {
+		addDiagnosticArgs(43, element, map[string]string{"element": name})
+		return
+	}
+	if inRowGroup == false {
+		s.err = processTableChild(element)
+		return
+	}
+
+	if name == "tr" {
+		s.err = checkRowLimits(element)
+	}
+	if s.err == nil {
+		s.err = processRowGroupChild(element)
+	}
+}
+
+// addRows add the rows of the elements to the identity of the table
+func (s *tableStream) addRows(elements *goquery.Selection) {
+	elements.Each(func(index int, elem *goquery.Selection) {
+		var rows = elem
+		if goquery.NodeName(elem) != "tr" {
+			rows = elem.ChildrenFiltered("tr")
+		}
+		rows.Each(func(index int, row *goquery.Selection) {
+			s.identity.addRow(row)
+		})
+	})
+}
+
+// addNested add the tables inside the element, they are parsed after the table
+func (s *tableStream) addNested(element *goquery.Selection) {
+	element.Find("table").Each(func(index int, table *goquery.Selection) {
+		s.nested = append(s.nested, table)
+	})
+}
+
+// finish end the table, the first tfoot is validated after the last row group like in the document
+func (s *tableStream) finish() {
+	s.flush()
+	s.endSection()
+	s.buildTable()
+
+	for i := 1; i < len(s.tfoot); i++ {
+		addDiagnostic(36, s.tfoot[i])
+	}
+	if len(s.tfoot) > 0 && s.err == nil {
+		s.err = processTableChild(s.tfoot[0])
+	}
+	endTable()
+}
+
+// numbered return the fingerprint of the table from his identity, the tables with the same identity are numbered
+func (s *tableStream) numbered(identity string) string {
+	s.fingerprints[identity]++
+	return numberedFingerprint(identity, s.fingerprints[identity])
+}
+
+// forgetRows drop the rows already validated of the row group. The parser only check that the table and
+// the row group have rows, and read the uid of the last cell of each column: the last row and the last cells are kept
+// without their links to the other rows and cells. A dropped row keep his parent, the diagnostics find his table
+func forgetRows(rowGroup *html.Node) {
+	for child := rowGroup.FirstChild; child != nil; {
+		var next = child.NextSibling
+		child.PrevSibling = nil
+		child.NextSibling = nil
+		child = next
+	}
+	rowGroup.FirstChild = nil
+	rowGroup.LastChild = nil
+
+	if len(groupZero.row) > 0 {
+		var last = groupZero.row[len(groupZero.row)-1]
+		groupZero.row = []Row{{
+			uid:    last.uid,
+			elem:   last.elem,
+			rowpos: last.rowpos,
+			etype:  last.etype,
+			level:  last.level,
+		}}
+	}
+	if len(currentRowGroup.row) > 0 {
+		var last = currentRowGroup.row[len(currentRowGroup.row)-1]
+		currentRowGroup.row = []RowGroup{{
+			uid:   last.uid,
+			elem:  last.elem,
+			etype: last.etype,
+			level: last.level,
+		}}
+	}
+	for i := range groupZero.col {
+		if len(groupZero.col[i].cell) > 0 {
+			var last = groupZero.col[i].cell[len(groupZero.col[i].cell)-1]
+			groupZero.col[i].cell = []Cell{{
+				uid:    last.uid,
+				elem:   last.elem,
+				rowpos: last.rowpos,
+				colpos: last.colpos,
+				width:  last.width,
+				height: last.height,
+				etype:  last.etype,
+			}}
+		}
+	}
+	readCells = []Cell{}
+}
@@ -0,0 +1,107 @@
+package tableparser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// IgnoreAttribute is the data attribute used to suppress rules on a table, a row group, a row or a cell,
+// the value is a comma separated list of rule codes or ids, an empty value suppress all the rules
+const IgnoreAttribute = "data-tablevalidator-ignore"
+
+// disableCommentRegexp match the comment placed just before a table to suppress rules,
+// like <!-- tablevalidator-disable 16,23 -->
+var disableCommentRegexp = regexp.MustCompile(`^\s*tablevalidator-disable(?:\s+([\w\s,-]*))?\s*$`)
+
+// splitSuppressedDiagnostics separate the suppressed diagnostics from the reported diagnostics
+func splitSuppressedDiagnostics(table *goquery.Selection, fingerprint string, diagnostics Diagnostics) (reported Diagnostics, suppressed Diagnostics) {
+	var tableRules, tableAll = disabledByComment(table)
+
+	// The suppressions of the options are set by table fingerprint
+	if rules, exists := options.Suppressions[fingerprint]; exists == true {
+		tableRules = append(tableRules, rules...)
+		tableAll = tableAll || len(rules) == 0
+	}
+	reported = Diagnostics{}
+	suppressed = Diagnostics{}
+
+	for _, diag := range diagnostics {
+		if tableAll || matchRule(tableRules, diag.Rule) || ignoredByAttribute(table, diag) {
+			suppressed = append(suppressed, diag)
+		} else {
+			reported = append(reported, diag)
+		}
+	}
+	return reported, suppressed
+}
+
+// disabledByComment return the rules listed in the disable comment before the table,
+// all is true when the comment does not list any rule
+func disabledByComment(table *goquery.Selection) (rules []string, all bool) {
+	if table.Length() == 0 {
+		return nil, false
+	}
+
+	for node := table.Nodes[0].PrevSibling; node != nil; node = node.PrevSibling {
+		if node.Type == html.TextNode && len(strings.TrimSpace(node.Data)) == 0 {
+			continue
+		}
+		if node.Type != html.CommentNode {
+			break
+		}
+
+		var match = disableCommentRegexp.FindStringSubmatch(node.Data)
+		if match == nil {
+			continue
+		}
+		rules = splitRuleList(match[1])
+		return rules, len(rules) == 0
+	}
+	return nil, false
+}
+
+// ignoredByAttribute check the ignore attribute on the element of the diagnostic, his parents and the table
+func ignoredByAttribute(table *goquery.Selection, diag *Diagnostic) bool {
+	var elements = table
+	if diag.Selection != nil && diag.Selection.Length() != 0 {
+		// Go up to the table, the parents outside of the table are not used
+		elements = diag.Selection.AddSelection(diag.Selection.ParentsUntilSelection(table)).AddSelection(table)
+	}
+
+	var ignored = false
+	elements.EachWithBreak(func(index int, elem *goquery.Selection) bool {
+		var attrVal, exists = elem.Attr(IgnoreAttribute)
+		if exists == false {
+			return true
+		}
+
+		var rules = splitRuleList(attrVal)
+		if len(rules) == 0 || matchRule(rules, diag.Rule) {
+			ignored = true
+			return false
+		}
+		return true
+	})
+	return ignored
+}
+
+// splitRuleList split a comma or space separated list of rule codes or ids
+func splitRuleList(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
+
+// matchRule check if the rule is in the list of codes or ids
+func matchRule(list []string, rule Rule) bool {
+	for _, item := range list {
+		if item == strconv.Itoa(rule.Code) || item == rule.ID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package tableparser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Type is the type computed by the parser for a cell, a row, a row group or a colgroup
+type Type int
+
+// Type values, they are the same as the etype used inside the parser
+const (
+	TypeUnknown Type = iota
+	TypeHeader
+	TypeData
+	TypeSummary
+	TypeKey
+	TypeDescription
+	TypeLayout
+	TypeGroupHeader
+)
+
+var typeNames = []string{"unknown", "header", "data", "summary", "key", "description", "layout", "group header"}
+
+func (t Type) String() string {
+	if t < 0 || int(t) >= len(typeNames) {
+		return typeNames[TypeUnknown]
+	}
+	return typeNames[t]
+}
+
+// Table is the parsed structure of a table
+type Table struct {
+	// Selection is the table element
+	Selection *goquery.Selection
+	// Fingerprint is the stable identifier of the table, see Fingerprint
+	Fingerprint string
+	// Caption is the caption, the descriptions and the accessible name of the table, see TableCaption
+	Caption Caption
+	// RowGroups are all the row groups in the document order, see RowGroupNode for the hierarchy
+	RowGroups []*RowGroupNode
+	// Footer is the tfoot row group, it is also in RowGroups. It is nil when the table does not have a tfoot
+	Footer *RowGroupNode
+	// ColGroups are the colgroups and the virtual groups made by the group header cells
+	ColGroups []*ColGroupNode
+	// Columns are all the columns of the table
+	Columns []*Column
+	// DescriptionCells are the cells that describe a header cell, see Cell.Describes
+	DescriptionCells []Cell
+	// KeyCells are the data cells used as a key for a row header cell, see Cell.Describes
+	KeyCells []Cell
+	// LayoutCells are the cells used only for the layout, like the empty top left corner cell
+	LayoutCells []Cell
+	// Diagnostics are the problems found in the table, the last one stopped the parser when it is a structure problem
+	Diagnostics Diagnostics
+	// Suppressed are the problems suppressed by a disable comment or an ignore attribute, see IgnoreAttribute
+	Suppressed Diagnostics
+
+	grid       [][]Cell
+	gridSource gridSource
+	rowHeaders map[*html.Node][]Cell
+}
+
+func newTable(table *goquery.Selection, fingerprint string) *Table {
+	var colgroups, columns = buildColGroupTree(colgroupFrame, groupZero.virtualColgroup, groupZero.col)
+	var diagnostics, suppressed = splitSuppressedDiagnostics(table, fingerprint, reportedDiagnostics(groupZero.diagnostics))
+
+	// The grid is built when it is used, the grid of a too large table is empty.
+	// The rows streamed by StreamReader are not kept, the table does not have a grid
+	var grid [][]Cell
+	var source gridSource
+	var rowHeaders = map[*html.Node][]Cell{}
+	if limitExceeded == true || streamedRows == true {
+		grid = [][]Cell{}
+	} else {
+		source = newGridSource(table)
+		rowHeaders = cellRowHeaders()
+	}
+
+	// The row group nodes are in the same order as the parsed row groups
+	if streamedRows == true {
+		for i := range lstRowGroup {
+			lstRowGroup[i].row = nil
+		}
+	}
+	var rowgroups = buildRowGroupTree(lstRowGroup)
+	var footer *RowGroupNode
+	for i, rowgroup := range lstRowGroup {
+		if groupZero.tfoot.uid != 0 && rowgroup.uid == groupZero.tfoot.uid {
+			footer = rowgroups[i]
+		}
+	}
+
+	return &Table{
+		Selection:   table,
+		Fingerprint: fingerprint,
+		Caption:     TableCaption(table),
+		RowGroups:   rowgroups,
+		Footer:      footer,
+		ColGroups:   colgroups,
+		Columns:     columns,
+
+		DescriptionCells: sortCells(groupZero.desccell),
+		KeyCells:         sortCells(groupZero.keycell),
+		LayoutCells:      sortCells(groupZero.layoutCell),
+		Diagnostics:      diagnostics,
+		Suppressed:       suppressed,
+		grid:             grid,
+		gridSource:       source,
+		rowHeaders:       rowHeaders,
+	}
+}
+
+// sortCells return a copy of the cells in the document order
+func sortCells(cells []Cell) []Cell {
+	var sorted = append([]Cell{}, cells...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].rowpos != sorted[j].rowpos {
+			return sorted[i].rowpos < sorted[j].rowpos
+		}
+		return sorted[i].colpos < sorted[j].colpos
+	})
+	return sorted
+}
+
+// RowGroupRoots return the row groups at the top of the row hierarchy
+func (t *Table) RowGroupRoots() []*RowGroupNode {
+	var roots = []*RowGroupNode{}
+	for _, node := range t.RowGroups {
+		if node.Parent == nil {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+// ColGroupRoots return the column groups at the top of the column hierarchy
+func (t *Table) ColGroupRoots() []*ColGroupNode {
+	var roots = []*ColGroupNode{}
+	for _, node := range t.ColGroups {
+		if node.Parent == nil {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+// Selection return the th or td element of the cell
+func (c Cell) Selection() *goquery.Selection {
+	return c.elem
+}
+
+// Type return the computed type of the cell
+func (c Cell) Type() Type {
+	return Type(c.etype)
+}
+
+// Level return the hierarchy level of the cell, used by the group header cells
+func (c Cell) Level() int {
+	return c.level
+}
+
+// RowPos return the row position of the cell, starting at 1
+func (c Cell) RowPos() int {
+	return c.rowpos
+}
+
+// ColPos return the column position of the cell, starting at 1
+func (c Cell) ColPos() int {
+	return c.colpos
+}
+
+// Width return the number of columns spanned by the cell
+func (c Cell) Width() int {
+	return c.width
+}
+
+// Height return the number of rows spanned by the cell
+func (c Cell) Height() int {
+	return c.height
+}
+
+// Text return the trimmed text of the cell
+func (c Cell) Text() string {
+	if c.elem == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.elem.Text())
+}
@@ -1,12 +1,10 @@
 package tableparser
 
 import (
-	"errors"
-	"regexp"
+	"context"
 	"strconv"
 	"strings"
-
-	"golang.org/x/net/html"
+	"sync"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -27,6 +25,11 @@ type GroupZero struct {
 	virtualColgroup  []Cell
 	colgrouphead     []ColGroup
 	theadRowStack    []Row
+	tfoot            RowGroup
+	desccell         []Cell
+	keycell          []Cell
+	layoutCell       []Cell
+	diagnostics      []*Diagnostic
 }
 
 // ColCaption use to caption text in col
@@ -69,6 +72,8 @@ type ColGroup struct {
 	headerLevel []Cell
 	cell        []Cell
 	dataheader  []Cell
+	// Header cells of the tfoot rows that summarize this column
+	summaryheader []Cell
 }
 
 // RowGroup is struct for rowgroup element
@@ -123,7 +128,8 @@ type Cell struct {
 	colgroup   ColGroup
 	summary    ColGroup
 	parent     ColGroup
-	// describe []Cell
+	// Header cells described by a description cell or represented by a key cell
+	describe      []Cell
 	keycell       []Cell
 	addrowheaders []Cell
 	addcolheaders []Cell
@@ -138,6 +144,10 @@ type Obj struct {
 	elem *goquery.Selection
 }
 
+// parserMutex guard the variables of the parser, the tables are parsed one at a time.
+// It is locked by the exported functions, the other functions of the package expect it locked
+var parserMutex sync.Mutex
+
 // Variable declaration
 var uidElem int
 var colgroupFrame []ColGroup
@@ -167,8 +177,100 @@ var groupZero = GroupZero{
 
 var obj = Obj{}
 
-// Init function
+// Init function, parse the table with the default options
 func Init(table *goquery.Selection) error {
+	return InitWithOptions(table, DefaultOptions())
+}
+
+// InitWithOptions parse the table with the given options
+func InitWithOptions(table *goquery.Selection, opts Options) error {
+	_, err := Parse(table, opts)
+	return err
+}
+
+// Parse the table with the given options and return his parsed structure,
+// the structure is returned even when the parsing stop on an error. The error
+// is the list of the warning and error diagnostics, all the diagnostics are in Table.Diagnostics
+func Parse(table *goquery.Selection, opts Options) (*Table, error) {
+	return ParseContext(context.Background(), table, opts)
+}
+
+// ParseContext parse the table like Parse, the parsing stop with the context error
+// when the context is canceled or when his deadline is exceeded.
+// It is safe for concurrent use, the tables are parsed one at a time
+func ParseContext(ctx context.Context, table *goquery.Selection, opts Options) (*Table, error) {
+	parserMutex.Lock()
+	defer parserMutex.Unlock()
+	return parseLocked(ctx, table, opts)
+}
+
+// parseLocked is ParseContext, with the parserMutex locked
+func parseLocked(ctx context.Context, table *goquery.Selection, opts Options) (*Table, error) {
+	parseContext = ctx
+	defer func() {
+		parseContext = context.Background()
+	}()
+
+	var err = parseTable(table, opts)
+	return tableResult(table, tableFingerprint(table), err)
+}
+
+// tableResult return the parsed structure of the table and his problems, err is the error of the parser
+func tableResult(table *goquery.Selection, fingerprint string, err error) (*Table, error) {
+	if diag, ok := err.(*Diagnostic); ok == true {
+		// The parser stopped on this problem
+		groupZero.diagnostics = append(groupZero.diagnostics, diag)
+	} else if err != nil {
+		return newTable(table, fingerprint), err
+	}
+
+	if options.FixDescribedBy == true {
+		linkDescribedBy(groupZero.desccell)
+		linkDescribedBy(groupZero.keycell)
+	}
+
+	// The info notes are only listed in the table diagnostics, they do not make the parsing fail
+	var result = newTable(table, fingerprint)
+	var problems = Diagnostics{}
+	for _, diag := range result.Diagnostics {
+		if diag.Severity >= SeverityWarning {
+			problems = append(problems, diag)
+		}
+	}
+	if len(problems) > 0 {
+		return result, problems
+	}
+	return result, nil
+}
+
+func parseTable(table *goquery.Selection, opts Options) error {
+	if err := beginTable(table, opts); err != nil {
+		return err
+	}
+
+	// Main Entry for the table parsing
+	// The tfoot summarize the whole table, it is always processed after the last tbody
+	// Only the first tfoot is parsed, the other tfoot are reported and ignored
+	var tfoot = structureChildren(table, "tfoot")
+	for i := 1; i < tfoot.Length(); i++ {
+		addDiagnostic(36, tfoot.Eq(i))
+	}
+	tfoot = tfoot.First()
+
+	reportIgnoredChildren(table)
+
+	var err error
+	structureChildren(table).Not("tfoot").AddSelection(tfoot).EachWithBreak(func(index int, element *goquery.Selection) bool {
+		err = processTableChild(element)
+		return err == nil
+	})
+
+	endTable()
+	return err
+}
+
+// beginTable reset the variables of the parser and check the table before his children are processed
+func beginTable(table *goquery.Selection, opts Options) error {
 	// doc *goquery.Document
 	// table := doc.Find("table")
 
@@ -190,6 +292,8 @@ func Init(table *goquery.Selection) error {
 	hassumMode = false
 	tfootOnProcess = false
 	lastHeadingSummaryColPos = -1
+	readCells = []Cell{}
+	readRowCount = 0
 
 	groupZero = GroupZero{
 		nbDescriptionRow: 0,
@@ -200,8 +304,19 @@ func Init(table *goquery.Selection) error {
 		elem: table,
 	}
 
+	options = opts
+	limitExceeded = false
+
+	// A too large table is not parsed
+	if err := checkLimits(table); err != nil {
+		return err
+	}
+	if err := parseContext.Err(); err != nil {
+		return err
+	}
+
 	// Check for hassum mode
-	hassumMode = table.HasClass("hassum")
+	hassumMode = isHassumMode(table, options.Hassum)
 
 	// Set the uid for the groupZero
 	uidElem = uidElem + 1
@@ -222,150 +337,128 @@ func Init(table *goquery.Selection) error {
 
 	groupZero.col = []ColGroup{}
 
-	// Main Entry for the table parsing
-	if table.Has("tfoot") != nil {
-		tfoot := table.Find("tfoot")
-		// table = table.Find("tfoot").Remove().End().ParentsUntil("~")
-		table.Find("tfoot").Remove()
-		table.Find("tbody").Parent().AppendSelection(tfoot)
-	}
+	return nil
+}
 
+// processTableChild process a child element of the table: a caption, a colgroup, a row group or a row
+func processTableChild(element *goquery.Selection) error {
 	var err error
-	table.Children().EachWithBreak(func(index int, element *goquery.Selection) bool {
-		var nodeName = strings.ToLower(goquery.NodeName(element))
-		if nodeName == "caption" {
-			err = processCaption(element)
-
-			if err != nil {
-				return false
-			}
-		} else if nodeName == "colgroup" {
-			err = processColgroup(element, -1)
-
-			if err != nil {
-				return false
-			}
-		} else if nodeName == "thead" {
-			currentRowGroupElement = element
-
-			// The table should not have any row at this point
-			if len(theadRowStack) != 0 || (groupZero.row != nil && len(groupZero.row) > 0) {
-				err = errors.New("warning\t26\tYou can not define any row before the thead group")
-				return false
-			}
-
-			stackRowHeader = true
-
-			// This is the rowgroup header, Colgroup type can not be defined here
-			element.Children().EachWithBreak(func(idx int, elem *goquery.Selection) bool {
-				if strings.ToLower(goquery.NodeName(elem)) != "tr" {
-					// ERROR
-					err = errors.New("warning\t27\tthead element need to only have tr element as his child")
-				}
-				err = processRow(elem)
-
-				if err != nil {
-					return false
-				}
-
-				return true
-			})
-
-			stackRowHeader = false
+	var nodeName = strings.ToLower(goquery.NodeName(element))
+	if nodeName == "caption" {
+		return processCaption(element)
+	} else if nodeName == "colgroup" {
+		return processColgroup(element, -1)
+	} else if nodeName == "thead" {
+		currentRowGroupElement = element
+
+		// The table should not have any row at this point
+		if len(theadRowStack) != 0 || (groupZero.row != nil && len(groupZero.row) > 0) {
+			return newDiagnostic(26, element)
+		}
 
-			if err != nil {
-				return false
-			}
+		stackRowHeader = true
+		reportIgnoredChildren(element)
 
-			// Here it"s not possible to Diggest the thead and the colgroup because we need the first data row to be half processed before
-		} else if nodeName == "tbody" || nodeName == "tfoot" {
-			if nodeName == "tfoot" {
-				tfootOnProcess = true
-			}
+		// This is the rowgroup header, Colgroup type can not be defined here
+		structureChildren(element).EachWithBreak(func(idx int, elem *goquery.Selection) bool {
+			err = processRowGroupChild(elem)
+			return err == nil
+		})
 
-			// Currently there are no specific support for tfoot element, the tfoot is understood as a normal tbody
-			currentRowGroupElement = element
-			err = initiateRowGroup()
+		stackRowHeader = false
 
-			if err != nil {
-				return false
-			}
+		// Here it"s not possible to Diggest the thead and the colgroup because we need the first data row to be half processed before
+		return err
+	} else if nodeName == "tbody" || nodeName == "tfoot" {
+		err = beginRowGroup(element)
+		if err != nil {
+			return err
+		}
 
-			/*
-			*
-			* First tbody = data
-			* All tbody with header === data
-			* Subsequent tbody without header === summary
-			*
-			 */
+		/*
+		*
+		* First tbody = data
+		* All tbody with header === data
+		* Subsequent tbody without header === summary
+		*
+		 */
+
+		// New row group
+		structureChildren(element).EachWithBreak(func(idx int, elem *goquery.Selection) bool {
+			err = processRowGroupChild(elem)
+			return err == nil
+		})
 
-			// New row group
-			element.Children().EachWithBreak(func(idx int, elem *goquery.Selection) bool {
-				if strings.ToLower(goquery.NodeName(elem)) != "tr" {
-					// ERROR
-					err = errors.New("warning\t27\tthead element need to only have tr element as his child")
-					return false
-				}
-				err = processRow(elem)
+		if err != nil {
+			return err
+		}
 
-				if err != nil {
-					return false
-				}
+		return endRowGroup()
+	} else if nodeName == "tr" {
+		// This are suppose to be a simple table
+		return processRow(element)
+	}
 
-				return true
-			})
+	// There is a DOM Structure error
+	return newDiagnostic(30, element)
+}
 
-			if err != nil {
-				return false
-			}
+// beginRowGroup start the tbody or the tfoot row group, his rows are processed by processRowGroupChild
+func beginRowGroup(element *goquery.Selection) error {
+	if strings.ToLower(goquery.NodeName(element)) == "tfoot" {
+		tfootOnProcess = true
+	}
 
-			err = finalizeRowGroup()
+	// The tfoot is a summary row group at level 0, see rowgroupSetup
+	currentRowGroupElement = element
+	if err := initiateRowGroup(); err != nil {
+		return err
+	}
 
-			if err != nil {
-				return false
-			}
+	reportIgnoredChildren(element)
+	return nil
+}
 
-			// Check for residual rowspan, there can not have cell that overflow on two or more rowgroup
-			for _, span := range spannedRow {
-				if span.uid != 0 && span.spanHeight > 0 {
-					// That row are spanned in 2 different row group
-					err = errors.New("warning\t29\tYou cannot span cell in 2 different rowgroup")
-					return false
-				}
-			}
+// processRowGroupChild process a child element of a row group, it should be a row
+func processRowGroupChild(element *goquery.Selection) error {
+	if strings.ToLower(goquery.NodeName(element)) != "tr" {
+		// ERROR
+		return newDiagnostic(27, element)
+	}
+	return processRow(element)
+}
 
-			spannedRow = map[int]Cell{}           /* Cleanup of any spanned row */
-			rowgroupHeaderRowStack = []RowGroup{} /* Remove any rowgroup header found. */
-		} else if nodeName == "tr" {
-			// This are suppose to be a simple table
-			err = processRow(element)
+// endRowGroup finalize the tbody or the tfoot row group started by beginRowGroup
+func endRowGroup() error {
+	var err = finalizeRowGroup()
+	if err != nil {
+		return err
+	}
 
-			if err != nil {
-				return false
-			}
-		} else {
-			// There is a DOM Structure error
-			err = errors.New("error\t30\tUse the appropriate table markup")
-			return false
+	// Check for residual rowspan, there can not have cell that overflow on two or more rowgroup
+	for _, span := range spannedRow {
+		if span.uid != 0 && span.spanHeight > 0 {
+			// That row are spanned in 2 different row group
+			return newDiagnostic(29, span.elem)
 		}
+	}
 
-		return true
-	})
+	spannedRow = map[int]Cell{}           /* Cleanup of any spanned row */
+	rowgroupHeaderRowStack = []RowGroup{} /* Remove any rowgroup header found. */
+	return nil
+}
 
+// endTable end the parsing of the table, after his last child
+func endTable() {
 	groupZero.theadRowStack = theadRowStack
 	groupZero.colgrp = nil
 
 	// addHeaders(groupZero)
-
-	return err
 }
 
 func processCaption(element *goquery.Selection) error {
 	groupZero.colcaption.elem = element
 	groupZero.rowcaption.elem = element
-	var caption *goquery.Selection
-	var captionFound = false
-	var description = []*goquery.Selection{}
 	var groupheadercell = GroupHeaderCell{
 		colcaption: groupZero.colcaption,
 		rowcaption: groupZero.rowcaption,
@@ -373,46 +466,13 @@ func processCaption(element *goquery.Selection) error {
 	}
 
 	// Extract the caption vs the description
-	// There are 2 techniques,
+	// There are 3 techniques, see splitCaption
 	//	Recommanded is encapsulate the caption with "strong"
 	//	Use Details/Summary element
 	//	Use a simple paragraph
-	if element.Children().Length() != 0 {
-		// Use the contents function to retrieve the caption
-		element.Contents().Each(func(index int, elem *goquery.Selection) {
-			// Text node
-			if caption == nil && elem.Nodes[0].Type == html.TextNode {
-				// Doesn't matter what it is, but this will be
-				// considered as the caption if is not empty
-				var re = regexp.MustCompile(`^\s+|\s+$`)
-				var captionText = re.ReplaceAllString(elem.Text(), "")
-				if len(captionText) != 0 {
-					caption = elem
-					captionFound = true
-					return
-				}
-				caption = nil
-			} else if caption == nil && elem.Nodes[0].Type == html.ElementNode {
-				// Doesn't matter what it is, the first children
-				// element will be considered as the caption
-				caption = elem
-				return
-			}
-		})
-
-		// Use the children function to retrieve the description
-		element.Children().Each(func(index int, elem *goquery.Selection) {
-			if captionFound {
-				description = append(description, elem)
-			} else {
-				captionFound = true
-			}
-		})
-	} else {
-		caption = element
-	}
+	// The elements referenced by aria-describedby, on the table or on the caption, are descriptions too
+	var caption, description = tableDescription(element.Closest("table"), element)
 
-	// Move the description in a wrapper if there is more than one element
 	if len(description) >= 1 {
 		groupheadercell.description = description
 	}
@@ -507,7 +567,7 @@ func processColgroup(element *goquery.Selection, nbvirtualcol int) error {
 		} else if nbvirtualcol != -1 {
 			width = nbvirtualcol
 		} else {
-			return errors.New("error\t31\tInternal Error, Number of virtual column must be set [func processColgroup()]")
+			return newDiagnostic(31, element)
 		}
 		colgroupspan = colgroupspan + width
 
@@ -560,9 +620,10 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 		// The first colgroup must match the colgroupHeaderColEnd
 		if len(colgroupFrame) > 0 && (colgroupFrame[0].start != 1 || (colgroupFrame[0].end != colgroupHeaderColEnd && colgroupFrame[0].end != (colgroupHeaderColEnd+1))) {
 			// Destroy any existing colgroup, because they are not valid
+			var colgroupElem = colgroupFrame[0].elem
 			colgroupFrame = []ColGroup{}
 
-			return errors.New("warning\t3\tthe first colgroup must be spanned to represent the header column group")
+			return newDiagnostic(3, colgroupElem)
 		}
 	} else {
 		// This mean that are no colgroup designated to be a colgroup header
@@ -575,6 +636,7 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 		theadRS = theadRowStack[i]
 		if theadRS.etype == 0 {
 			theadRS.etype = 1
+			theadRowStack[i].etype = 1
 		}
 		var jLen = len(theadRS.cell)
 		for j := 0; j < jLen; j++ {
@@ -583,15 +645,23 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 
 			// check if we have a layout cell at the top, left
 			htmlstr, _ := cell.elem.Html()
-			if i == 0 && j == 0 && len(htmlstr) == 0 {
+			if i == 0 && j == 0 && len(strings.TrimSpace(htmlstr)) == 0 {
 				// That is a layout cell
 				cell.etype = 6
+				addLayoutCell(cell)
+				setTheadCell(cell)
 
-				// Missing snippet
-				// if ( !groupZero.layoutCell ) {
-				// 	groupZero.layoutCell = []
-				// }
-				// groupZero.layoutCell.push( cell )
+				// Those rules do not affect the table structure, the parsing can continue
+				if options.IgnoreLayoutCell == false {
+					if strings.ToLower(goquery.NodeName(cell.elem)) == "th" {
+						addDiagnostic(38, cell.elem)
+					}
+
+					// Without row header column, there is no header colgroup to compare with
+					if colgroupHeaderColEnd > 0 && cell.colpos+cell.width-1 > colgroupHeaderColEnd {
+						addDiagnostic(39, cell.elem)
+					}
+				}
 
 				j = cell.width - 1
 				if j >= jLen {
@@ -600,6 +670,8 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 			}
 
 			// Check the next row to see if they have a corresponding description cell
+			theadRSNext = Row{}
+			theadRSNextCell = Cell{}
 			if len(theadRowStack) > i+1 {
 				theadRSNext = theadRowStack[i+1]
 			}
@@ -608,34 +680,37 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 				theadRSNextCell = theadRSNext.cell[j]
 			}
 
-			if len(cell.descCell) > 0 &&
+			if len(cell.descCell) == 0 &&
 				strings.ToLower(goquery.NodeName(cell.elem)) == "th" &&
-				cell.etype != 0 &&
+				cell.etype == 0 &&
 				theadRSNext.uid != 0 &&
 				theadRSNext.uid != cell.uid &&
 				theadRSNextCell.uid != 0 &&
-				theadRSNextCell.etype != 0 &&
+				theadRSNextCell.etype == 0 &&
 				strings.ToLower(goquery.NodeName(theadRSNextCell.elem)) == "td" &&
 				theadRSNextCell.width == cell.width &&
 				theadRSNextCell.height == 1 {
 				// Mark the next row as a row description
 				theadRSNext.etype = 5
+				theadRowStack[i+1].etype = 5
 
 				// Mark the cell as a cell description
 				theadRSNextCell.etype = 5
+				theadRSNextCell.scope = "col"
 				theadRSNextCell.row = theadRS
+				theadRSNextCell.describe = []Cell{cell}
 				cell.descCell = []Cell{}
 				cell.descCell = append(cell.descCell, theadRSNextCell)
+				setTheadCell(theadRSNextCell)
 
 				// Add the description cell to the complete listing
+				groupZero.desccell = append(groupZero.desccell, theadRSNextCell)
 
-				// Missing snippet
-				// if ( !groupZero.desccell ) {
-				// 	groupZero.desccell = [];
-				// }
-				// groupZero.desccell.push( theadRSNextCell );
-
-				j = cell.width - 1
+				if cell.etype == 0 {
+					cell.etype = 1
+				}
+				setTheadCell(cell)
+				j = j + cell.width - 1
 				if j >= jLen {
 					break
 				}
@@ -644,6 +719,7 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 			if cell.etype == 0 {
 				cell.etype = 1
 			}
+			setTheadCell(cell)
 		}
 	}
 
@@ -657,7 +733,7 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 			for j := 0; j != jLen; j++ {
 				cell = theadRS.cell[j]
 				if cell.etype != 5 && cell.etype != 6 && cell.height != 1 {
-					return errors.New("warning\t4\tYou have an invalid cell inside a row description")
+					return newDiagnostic(4, cell.elem)
 				}
 
 				// Check the row before and modify their height value
@@ -688,13 +764,14 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 
 			// Set colgroup data type
 			etype: 2,
+			level: 1,
 			col:   []ColGroup{},
 		}
 		uidElem++
 		// groupZero.allParserObj = append(groupZero.allParserObj, colgroup)
 
 		if colgroup.start > colgroup.end {
-			return errors.New("warning\t5\tYou need at least one data colgroup, review your table structure")
+			return newDiagnostic(5, obj.elem)
 		}
 
 		dataColgroup = colgroup
@@ -804,6 +881,7 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 					}
 				}
 			}
+			groupZero.col[i] = gzCol
 		}
 	} else {
 		// They exist colgroup element,
@@ -831,12 +909,13 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 		currColgroupStructure = []Cell{}
 		bigTotalColgroupFound = false
 
-		for _, curColgroupFrame := range colgroupFrame {
+		for cgIdx := range colgroupFrame {
+			var curColgroupFrame = colgroupFrame[cgIdx]
 			var groupLevel = -1
 			var cgrp Cell
 
 			if bigTotalColgroupFound == true || (len(groupZero.colgrp) > 0 && len(groupZero.colgrp[0]) > 0) {
-				return errors.New("error\t6\tThe Lowest column group level have been found, You may have an error in you column structure")
+				return newDiagnostic(6, curColgroupFrame.elem)
 			}
 
 			for _, column := range curColgroupFrame.col {
@@ -853,7 +932,7 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 
 			if curColgroupFrame.start < currColPos {
 				if colgroupHeaderColEnd != curColgroupFrame.end {
-					return errors.New("warning\t7\tThe initial colgroup should group all the header, there are no place for any data cell")
+					return newDiagnostic(7, curColgroupFrame.elem)
 				}
 
 				// Skip this colgroup, this should happened only once and should represent the header colgroup
@@ -861,17 +940,24 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 				// Assign the headers for this group
 				for i := 0; i != len(curColgroupFrame.col); i++ {
 					gzCol = curColgroupFrame.col[i]
+					gzCol.etype = 1
 					gzCol.header = []Cell{}
 					for j := 0; j != len(tmpStack); j++ {
-						for m := gzCol.start; m <= gzCol.end; m++ {
+						for m := gzCol.start; m <= gzCol.end && m <= len(tmpStack[j].cell); m++ {
 							if (j == 0 || (j > 0 && tmpStack[j].cell[m-1].uid != tmpStack[j-1].cell[m-1].uid)) &&
 								tmpStack[j].cell[m-1].etype == 1 {
 								gzCol.header = append(gzCol.header, tmpStack[j].cell[m-1])
 							}
 						}
 					}
+					curColgroupFrame.col[i] = gzCol
+					updateColumn(gzCol)
 				}
-				return nil
+				curColgroupFrame.etype = 1
+				colgroupFrame[cgIdx] = curColgroupFrame
+
+				// In the javascript version, this return act as a continue in the jQuery each loop
+				continue
 			}
 
 			// get the colgroup level
@@ -880,7 +966,7 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 					tmpStackCell = tmpStack[i].cell[curColgroupFrame.end-1]
 					if tmpStackCell.uid == 0 && curColgroupFrame.end > len(tmpStack[i].cell) {
 						// Number of column are not corresponding to the table width
-						return errors.New("warning\t3\tThe first colgroup must be spanned to represent the header column group")
+						return newDiagnostic(3, curColgroupFrame.elem)
 					}
 					if (tmpStackCell.colpos+tmpStackCell.width-1) == curColgroupFrame.end &&
 						tmpStackCell.colpos >= curColgroupFrame.start {
@@ -892,7 +978,7 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 					}
 				} else {
 					// Number of column are not corresponding to the table width
-					return errors.New("warning\t3\tThe first colgroup must be spanned to represent the header column group")
+					return newDiagnostic(3, curColgroupFrame.elem)
 				}
 			}
 
@@ -912,20 +998,20 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 					tmpStackCell = tmpStackCurr.cell[j]
 					if tmpStackCell.colpos < curColgroupFrame.start ||
 						(tmpStackCell.colpos+tmpStackCell.width-1) > curColgroupFrame.end {
-						return errors.New("error\t9\tError in you header row group, there are cell that are crossing more than one colgroup")
+						return newDiagnostic(9, tmpStackCell.elem)
 					}
 				}
 			}
 
 			// Add virtual colgroup Based on the top header
-			for i := len(currColgroupStructure); i != groupLevel-1; i++ {
+			for i := len(currColgroupStructure); i < groupLevel-1; i++ {
 				tmpStackCell = tmpStack[i].cell[curColgroupFrame.start-1]
 
 				// Use the top cell at level minus 1, that cell must be larger
 				if tmpStackCell.uid != tmpStack[i].cell[curColgroupFrame.end-1].uid ||
 					tmpStackCell.colpos > curColgroupFrame.start ||
 					tmpStackCell.colpos+tmpStackCell.width-1 < curColgroupFrame.end {
-					return errors.New("error\t10\tThe header group cell used to represent the data at level must encapsulate his group")
+					return newDiagnostic(10, tmpStackCell.elem)
 				}
 
 				// Convert the header in a group header cell
@@ -1077,7 +1163,8 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 				groupZero.col = []ColGroup{}
 			}
 
-			for _, column := range curColgroupFrame.col {
+			for k := range curColgroupFrame.col {
+				var column = curColgroupFrame.col[k]
 				var colpos int
 				var cellWidth int
 				var colHeaderLen int
@@ -1110,7 +1197,12 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 						}
 					}
 				}
+
+				// In Go, the column is a copy, so we must assign it again
+				curColgroupFrame.col[k] = column
+				updateColumn(column)
 			}
+			colgroupFrame[cgIdx] = curColgroupFrame
 		}
 
 		if groupZero.virtualColgroup == nil {
@@ -1120,7 +1212,7 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 		// Set the Virtual Group Header Cell, if any
 		for _, vGroupHeaderCell := range groupZero.virtualColgroup {
 			// Set the headerLevel at the appropriate column
-			for i := vGroupHeaderCell.start - 1; i < vGroupHeaderCell.end; i++ {
+			for i := vGroupHeaderCell.start - 1; i < vGroupHeaderCell.end && i < len(groupZero.col); i++ {
 				if groupZero.col[i].headerLevel == nil {
 					groupZero.col[i].headerLevel = []Cell{}
 				}
@@ -1136,11 +1228,44 @@ func processRowgroupHeader(colgroupHeaderColEnd int) error {
 
 		// Set the first colgroup type :-)
 		groupZero.colgrouphead[0].etype = 1
+		colgroupFrame[0].etype = 1
 	}
 
 	return nil
 }
 
+// addLayoutCell add the cell in the layout cell listing, a spanned cell is added only once
+func addLayoutCell(cell Cell) {
+	for _, layoutCell := range groupZero.layoutCell {
+		if layoutCell.uid == cell.uid {
+			return
+		}
+	}
+	groupZero.layoutCell = append(groupZero.layoutCell, cell)
+}
+
+// setTheadCell replace the cell in all the slots it span in the thead rows.
+// In javascript the cell is an object shared by all his slots, in Go each slot have his copy
+func setTheadCell(cell Cell) {
+	for i := range theadRowStack {
+		for j := range theadRowStack[i].cell {
+			if theadRowStack[i].cell[j].uid == cell.uid {
+				theadRowStack[i].cell[j] = cell
+			}
+		}
+	}
+}
+
+// updateColumn replace the column in the groupZero by his updated value
+func updateColumn(column ColGroup) {
+	for i := range groupZero.col {
+		if groupZero.col[i].uid == column.uid {
+			groupZero.col[i] = column
+			return
+		}
+	}
+}
+
 func finalizeRowGroup() error {
 	var err error
 	// Check if the current rowgroup has been go in the rowgroup setup, if not we do
@@ -1153,6 +1278,11 @@ func finalizeRowGroup() error {
 	// Update, if needed, each row and cell to take in consideration the new row group level
 	// Add the row group in the groupZero Collection
 	lstRowGroup = append(lstRowGroup, currentRowGroup)
+
+	// The tfoot row group is the summary of the whole table
+	if tfootOnProcess == true {
+		groupZero.tfoot = currentRowGroup
+	}
 	currentRowGroup = RowGroup{}
 
 	return err
@@ -1295,7 +1425,7 @@ func rowgroupSetup(forceDataGroup bool) error {
 				}
 
 				if currentRowGroup.level < 0 {
-					return errors.New("warning\t12\tLast summary row group already found")
+					return newDiagnostic(12, currentRowGroup.elem)
 				}
 
 				// Set the header level with the previous row group
@@ -1308,7 +1438,7 @@ func rowgroupSetup(forceDataGroup bool) error {
 				// Error
 				// currentRowGroup.level = "Error, not calculated"
 				currentRowGroup.level = -1
-				return errors.New("warning\t13\tError, Row group not calculated")
+				return newDiagnostic(13, currentRowGroup.elem)
 			}
 		} else {
 			currentRowGroup.level = len(rowgroupHeaderRowStack) + 1
@@ -1325,7 +1455,7 @@ func rowgroupSetup(forceDataGroup bool) error {
 	rowgroupHeaderRowStack = []RowGroup{}
 
 	if currentRowGroup.level < 0 {
-		err = errors.New("warning\t14\ttr element need to only have th or td element as his child")
+		err = newDiagnostic(14, currentRowGroup.elem)
 	}
 
 	return err
@@ -1334,6 +1464,11 @@ func rowgroupSetup(forceDataGroup bool) error {
 func processRow(element *goquery.Selection) error {
 	// In this function there are a possible confusion about the colgroup variable name used here vs the real colgroup table,
 	// In this function the colgroup is used when there are no header cell.
+	// Stop the parsing when the context is canceled
+	if err := parseContext.Err(); err != nil {
+		return err
+	}
+
 	currentRowPos = currentRowPos + 1
 	var columnPost = 1
 	var lastCellType = ""
@@ -1364,7 +1499,8 @@ func processRow(element *goquery.Selection) error {
 
 	var err error
 	// Read the row
-	element.Children().Each(func(index int, elem *goquery.Selection) {
+	reportIgnoredChildren(element)
+	structureChildren(element).Each(func(index int, elem *goquery.Selection) {
 		var width = 1
 		var height = 1
 		var headerCell Cell
@@ -1440,7 +1576,7 @@ func processRow(element *goquery.Selection) error {
 			columnPost = columnPost + dataCell.width
 			break
 		default:
-			err = errors.New("warning\t15\ttr element need to only have th or td element as his child")
+			err = newDiagnostic(15, elem)
 			break
 		}
 
@@ -1453,6 +1589,7 @@ func processRow(element *goquery.Selection) error {
 
 	// Check for any spanned cell
 	fnParseSpannedRowCell(&columnPost, &lastCellType, &row, &colgroup, &lastHeadingColPos)
+	addReadRow(row)
 
 	// Check if this the number of column for this row are equal to the other
 	if tableCellWidth == 0 {
@@ -1461,7 +1598,14 @@ func processRow(element *goquery.Selection) error {
 	}
 
 	if tableCellWidth != len(row.cell) {
-		return errors.New("warning\t16\tThe row do not have a good width")
+		var args = map[string]string{
+			"width":    strconv.Itoa(len(row.cell)),
+			"expected": strconv.Itoa(tableCellWidth),
+		}
+		if tfootOnProcess == true {
+			return newDiagnosticArgs(37, element, args)
+		}
+		return newDiagnosticArgs(16, element, args)
 	}
 
 	// Check if we are into a thead rowgroup, if yes we stop here.
@@ -1476,7 +1620,8 @@ func processRow(element *goquery.Selection) error {
 	//
 	// Diggest the row
 	//
-	if lastCellType == "th" {
+	// Any row in the tfoot are a summary row, even if it ends with a header cell
+	if lastCellType == "th" && tfootOnProcess == false {
 		// Digest the row header
 		row.etype = 1
 
@@ -1487,18 +1632,18 @@ func processRow(element *goquery.Selection) error {
 				// Valid row header for the row group header
 				// REQUIRED: That cell need to be empty
 				var htmlVal, _ = row.colgroup[0].cell[0].elem.Html()
-				if len(htmlVal) == 0 {
+				if len(strings.TrimSpace(htmlVal)) == 0 || options.IgnoreLayoutCell == true {
 					// We stack the row
 					theadRowStack = append(theadRowStack, row)
 					// We do not go further
 					return nil
 				}
 
-				return errors.New("warning\t17\tThe layout cell is not empty")
+				return newDiagnostic(17, row.colgroup[0].cell[0].elem)
 			}
 
 			// Invalid row header
-			return errors.New("warning\t18\tRow group header not well structured")
+			return newDiagnostic(18, element)
 		}
 
 		if len(row.colgroup) == 1 {
@@ -1513,7 +1658,7 @@ func processRow(element *goquery.Selection) error {
 				}
 
 				// Bad row, remove the row or split the table
-				return errors.New("warning\t18\tRow group header not well structured")
+				return newDiagnostic(18, element)
 			}
 
 			if currentRowPos != 1 || row.cell[0].uid == row.cell[len(row.cell)-1].uid {
@@ -1533,11 +1678,11 @@ func processRow(element *goquery.Selection) error {
 				return nil
 			}
 
-			return errors.New("warning\t18\tRow group header not well structured")
+			return newDiagnostic(18, element)
 		}
 
 		if len(row.colgroup) > 1 && currentRowPos != 1 {
-			return errors.New("warning\t21\tMove the row used as the column cell heading in the thead row group")
+			return newDiagnostic(21, element)
 		}
 		//
 		// If Valid, process the row
@@ -1555,21 +1700,13 @@ func processRow(element *goquery.Selection) error {
 			row.etype = 5
 			row.cell[0].etype = 5
 			row.cell[0].row = row
-
-			// Missing snippet
-			// if ( !row.cell[ 0 ].describe ) {
-			// 	row.cell[ 0 ].describe = [];
-			// }
+			row.cell[0].describe = []Cell{}
 
 			rowgroupHeaderRowStack[len(rowgroupHeaderRowStack)-1].cell[0].descCell = []Cell{}
 			rowgroupHeaderRowStack[len(rowgroupHeaderRowStack)-1].cell[0].descCell = append(rowgroupHeaderRowStack[len(rowgroupHeaderRowStack)-1].cell[0].descCell, row.cell[0])
-			// row.cell[0].describe = append(row.cell[0].describe, rowgroupHeaderRowStack[len(rowgroupHeaderRowStack) - 1].cell[0])
+			row.cell[0].describe = append(row.cell[0].describe, rowgroupHeaderRowStack[len(rowgroupHeaderRowStack)-1].cell[0])
 
-			// Missing snippet
-			// if ( !groupZero.desccell ) {
-			// 	groupZero.desccell = [];
-			// }
-			// groupZero.desccell.push( row.cell[ 0 ] );
+			groupZero.desccell = append(groupZero.desccell, row.cell[0])
 
 			// FYI - We do not push this row in any stack because this row is a description row
 			// Stop the processing for this row
@@ -1608,7 +1745,8 @@ func processRow(element *goquery.Selection) error {
 		// Missing snippet
 		// row.rowgroup = currentRowGroup
 
-		if currentRowGroup.lastHeadingColPos != lastHeadingColPos {
+		// There are no virtual row group inside the tfoot, all the rows summarize the whole table
+		if currentRowGroup.lastHeadingColPos != lastHeadingColPos && tfootOnProcess == false {
 			if (lastHeadingSummaryColPos <= 0 && currentRowGroup.lastHeadingColPos < lastHeadingColPos) ||
 				(lastHeadingSummaryColPos > 0 && lastHeadingSummaryColPos == lastHeadingColPos) {
 				// This is a virtual summary row group
@@ -1616,7 +1754,7 @@ func processRow(element *goquery.Selection) error {
 				for _, cell := range spannedRow {
 					if cell.spanHeight > 0 {
 						// That row are spanned in 2 different row group
-						return errors.New("warning\t29\tYou cannot span cell in 2 different rowgroup")
+						return newDiagnostic(29, cell.elem)
 					}
 				}
 
@@ -1650,7 +1788,7 @@ func processRow(element *goquery.Selection) error {
 				// Check for residual rowspan, there can not have cell that overflow on two or more rowgroup
 				for _, cell := range spannedRow {
 					if cell.spanHeight > 0 {
-						return errors.New("warning\t29\tYou cannot span cell in 2 different rowgroup")
+						return newDiagnostic(29, cell.elem)
 					}
 				}
 
@@ -1679,9 +1817,9 @@ func processRow(element *goquery.Selection) error {
 				// Reset the current row type
 				row.etype = currentRowGroup.etype
 
-				return errors.New("warning\t34\tMark properly your data row group")
+				return newDiagnostic(34, element)
 			} else {
-				return errors.New("warning\t32\tCheck your row cell headers structure")
+				return newDiagnostic(32, element)
 			}
 		}
 
@@ -1711,25 +1849,15 @@ func processRow(element *goquery.Selection) error {
 						row.cell[i-1].descCell = []Cell{}
 						row.cell[i-1].descCell = append(row.cell[i-1].descCell, row.cell[i])
 
-						// Missing snippet
-
-						// if len(row.cell[i].describe) == 0 {
-						// 	row.cell[i].describe = []Cell{}
-						// }
-						// row.cell[i].describe = append(row.cell[i].describe, row.cell[i - 1])
-
-						// if len(row.desccell) == 0 {
-						// 	row.desccell = []Cell{}
-						// }
-						// row.desccell = append(row.desccell, row.cell[i])
-
-						// if ( !groupZero.desccell ) {
-						// 	groupZero.desccell = [];
-						// }
-						// groupZero.desccell.push( row.cell[ i ] );
+						if len(row.cell[i].describe) == 0 {
+							row.cell[i].describe = []Cell{}
+						}
+						row.cell[i].describe = append(row.cell[i].describe, row.cell[i-1])
 
 						// Specify the scope of this description cell
 						row.cell[i].scope = "row"
+
+						groupZero.desccell = append(groupZero.desccell, row.cell[i])
 					}
 
 					// Check if this cell can be an key cell associated to an cell heading
@@ -1747,7 +1875,7 @@ func processRow(element *goquery.Selection) error {
 					if rowheader.uid > 0 && rowheader.uid != row.cell[i].uid {
 						if rowheader.height >= row.cell[i].height {
 							if rowheader.height == row.cell[i].height {
-								return errors.New("warning\t23\tAvoid the use of have paralel row headers, it's recommended do a cell merge to fix it")
+								return newDiagnostic(23, row.cell[i].elem)
 							}
 
 							// The current cell are a child of the previous rowheader
@@ -1764,7 +1892,7 @@ func processRow(element *goquery.Selection) error {
 							headingRowCell = append(headingRowCell, row.cell[i])
 						} else {
 							// This case are either paralel heading of growing header, this are an error.
-							return errors.New("warning\t24\tFor a data row, the heading hiearchy need to be the Generic to the specific")
+							return newDiagnostic(24, row.cell[i].elem)
 						}
 					}
 
@@ -1776,8 +1904,18 @@ func processRow(element *goquery.Selection) error {
 					for j := 0; j < len(colKeyCell); j++ {
 						if colKeyCell[j].etype == 0 && len(row.cell[i].keycell) == 0 && colKeyCell[j].height == row.cell[i].height {
 							colKeyCell[j].etype = 4
+							colKeyCell[j].describe = []Cell{row.cell[i]}
 							row.cell[i].keycell = []Cell{}
 							row.cell[i].keycell = append(row.cell[i].keycell, colKeyCell[j])
+
+							// Set the type in the row slots of the key cell
+							for k := 0; k < i; k++ {
+								if row.cell[k].uid == colKeyCell[j].uid {
+									row.cell[k].etype = 4
+								}
+							}
+
+							groupZero.keycell = append(groupZero.keycell, colKeyCell[j])
 						}
 					}
 				}
@@ -1786,7 +1924,7 @@ func processRow(element *goquery.Selection) error {
 			// All the cell that have no "type" in the colKeyCell collection are problematic cells
 			for _, cell := range colKeyCell {
 				if cell.etype == 0 {
-					return errors.New("warning\t25\tYou have a problematic key cell")
+					return newDiagnostic(25, cell.elem)
 				}
 			}
 
@@ -1850,6 +1988,7 @@ func processRow(element *goquery.Selection) error {
 					// Test if this cell is a layout cell
 					if row.etype == 3 && colgroupFrame[j].etype == 3 && len(row.cell[i].elem.Text()) == 0 {
 						row.cell[i].etype = 6
+						addLayoutCell(row.cell[i])
 					}
 				}
 				isDataColgroupType = !isDataColgroupType
@@ -1898,13 +2037,22 @@ func processRow(element *goquery.Selection) error {
 							}
 							groupZero.col[i].cell = append(groupZero.col[i].cell, row.cell[j])
 						} else {
-							return errors.New("warning\t35\tColumn, col element, are not correctly defined")
+							return newDiagnostic(35, element)
 						}
 					}
 				}
 			}
 		}
 
+		// Associate the header cells of the tfoot row with the data columns they summarize
+		if tfootOnProcess == true && len(row.header) > 0 {
+			for i := 0; i < len(groupZero.col); i++ {
+				if groupZero.col[i].start > lastHeadingColPos {
+					groupZero.col[i].summaryheader = append(groupZero.col[i].summaryheader, row.header...)
+				}
+			}
+		}
+
 		// Associate the row with the cell and Colgroup/Col association
 		for i := 0; i < len(row.cell); i++ {
 			if row.cell[i].row.uid == 0 {
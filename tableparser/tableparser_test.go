@@ -0,0 +1,1444 @@
+package tableparser
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// parseFixture parse the first table of the html with the options
+func parseFixture(t *testing.T, source string, opts Options) *Table {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, _ := Parse(doc.Find("table").First(), opts)
+	if table == nil {
+		t.Fatal("no table returned")
+	}
+	return table
+}
+
+// diagnosticCodes return the rule codes of the diagnostics in their order
+func diagnosticCodes(diagnostics Diagnostics) []int {
+	var codes = []int{}
+	for _, diag := range diagnostics {
+		codes = append(codes, diag.Rule.Code)
+	}
+	return codes
+}
+
+func TestTfoot(t *testing.T) {
+	var tests = []struct {
+		name       string
+		html       string
+		codes      []int
+		footerRows int
+		lastGroup  Type
+	}{
+		{
+			name: "tfoot before tbody",
+			html: `<table>
+				<thead><tr><th>Item</th><th>Price</th></tr></thead>
+				<tfoot><tr><th>Total</th><td>3</td></tr></tfoot>
+				<tbody><tr><th>A</th><td>1</td></tr><tr><th>B</th><td>2</td></tr></tbody>
+			</table>`,
+			codes:      []int{},
+			footerRows: 1,
+			lastGroup:  TypeSummary,
+		},
+		{
+			name: "second tfoot is ignored",
+			html: `<table>
+				<thead><tr><th>Item</th><th>Price</th></tr></thead>
+				<tbody><tr><th>A</th><td>1</td></tr></tbody>
+				<tfoot><tr><th>Total</th><td>1</td></tr></tfoot>
+				<tfoot><tr><th>Other</th><td>1</td></tr><tr><th>Other</th><td>1</td></tr></tfoot>
+			</table>`,
+			codes:      []int{36},
+			footerRows: 1,
+			lastGroup:  TypeSummary,
+		},
+		{
+			name: "tfoot row width",
+			html: `<table>
+				<thead><tr><th>Item</th><th>Price</th><th>Tax</th></tr></thead>
+				<tbody><tr><th>A</th><td>1</td><td>0</td></tr></tbody>
+				<tfoot><tr><th>Total</th><td>1</td></tr></tfoot>
+			</table>`,
+			// The parser stop on the tfoot row, the row group is not complete
+			codes:      []int{37},
+			footerRows: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var table = parseFixture(t, test.html, DefaultOptions())
+			if codes := diagnosticCodes(table.Diagnostics); reflect.DeepEqual(codes, test.codes) == false {
+				t.Fatalf("codes = %v, want %v", codes, test.codes)
+			}
+			if test.footerRows == 0 {
+				if table.Footer != nil {
+					t.Error("footer is not nil")
+				}
+				return
+			}
+			if table.Footer == nil {
+				t.Fatal("no footer")
+			}
+			if len(table.Footer.Rows) != test.footerRows {
+				t.Errorf("footer rows = %d, want %d", len(table.Footer.Rows), test.footerRows)
+			}
+			if table.Footer.Type != test.lastGroup || table.Footer.Level != 0 {
+				t.Errorf("footer = %v level %d, want %v level 0", table.Footer.Type, table.Footer.Level, test.lastGroup)
+			}
+			if last := table.RowGroups[len(table.RowGroups)-1]; last != table.Footer {
+				t.Error("the footer is not the last row group")
+			}
+		})
+	}
+}
+
+func TestTfootWidthMessage(t *testing.T) {
+	var table = parseFixture(t, `<table>
+		<tr><th>Item</th><th>Price</th><th>Tax</th></tr>
+		<tr><th>A</th><td>1</td><td>0</td></tr>
+		<tfoot><tr><th>Total</th><td>1</td></tr></tfoot>
+	</table>`, DefaultOptions())
+
+	if len(table.Diagnostics) != 1 || table.Diagnostics[0].Rule.Code != 37 {
+		t.Fatalf("diagnostics = %v, want the code 37", table.Diagnostics)
+	}
+	var diag = table.Diagnostics[0]
+	if diag.Args["width"] != "2" || diag.Args["expected"] != "3" {
+		t.Errorf("args = %v, want width 2 and expected 3", diag.Args)
+	}
+	if goquery.NodeName(diag.Selection) != "tr" {
+		t.Errorf("selection = %s, want the tfoot row", goquery.NodeName(diag.Selection))
+	}
+}
+
+func TestNoTfoot(t *testing.T) {
+	var table = parseFixture(t, `<table><tr><th>A</th><td>1</td></tr></table>`, DefaultOptions())
+	if table.Footer != nil {
+		t.Error("footer is not nil")
+	}
+}
+
+// cellTexts return the text of the cells joined by a comma
+func cellTexts(cells []Cell) string {
+	var texts = []string{}
+	for _, cell := range cells {
+		texts = append(texts, cell.Text())
+	}
+	return strings.Join(texts, ",")
+}
+
+// The header association of the columns with a thead of two rows. Before the header association fix,
+// the cells of the thead were updated only in a copy, so no column had a header, and the parser stopped
+// at the header colgroup, so the data colgroups had no header and their columns were missing
+func TestColumnHeaders(t *testing.T) {
+	var thead = `<thead>
+		<tr><th rowspan="2">Region</th><th colspan="2">Sales</th></tr>
+		<tr><th>Q1</th><th>Q2</th></tr>
+	</thead>
+	<tbody><tr><th>East</th><td>1</td><td>2</td></tr></tbody>`
+
+	var tests = []struct {
+		name         string
+		html         string
+		headers      []string
+		groupHeaders []string
+		levels       []int
+		groups       []string
+	}{
+		{
+			name:         "without colgroup",
+			html:         `<table>` + thead + `</table>`,
+			headers:      []string{"Region", "Sales,Q1", "Sales,Q2"},
+			groupHeaders: []string{"", "", ""},
+			levels:       []int{0, 1, 1},
+			groups:       []string{"header 0 1-1 ", "data 1 2-3 "},
+		},
+		{
+			name:         "with colgroups",
+			html:         `<table><colgroup></colgroup><colgroup span="2"></colgroup>` + thead + `</table>`,
+			headers:      []string{"Region", "Sales,Q1", "Sales,Q2"},
+			groupHeaders: []string{"", "", ""},
+			levels:       []int{0, 1, 1},
+			groups:       []string{"header 0 1-1 ", "data 1 2-3 Sales,Q1,Q2"},
+		},
+		{
+			name: "with a group header",
+			html: `<table><colgroup></colgroup><colgroup span="2"></colgroup><colgroup span="2"></colgroup>
+				<thead>
+					<tr><th rowspan="2">Region</th><th colspan="4">Sales</th></tr>
+					<tr><th>Q1</th><th>Q2</th><th>Q3</th><th>Q4</th></tr>
+				</thead>
+				<tbody><tr><th>East</th><td>1</td><td>2</td><td>3</td><td>4</td></tr></tbody>
+			</table>`,
+			headers:      []string{"Region", "Q1", "Q2", "Q3", "Q4"},
+			groupHeaders: []string{"", "Sales", "Sales", "Sales", "Sales"},
+			levels:       []int{0, 2, 2, 2, 2},
+			groups:       []string{"group header 1 2-5 Sales", "header 0 1-1 ", "data 2 2-3 Sales,Q1,Q2", "data 2 4-5 Sales,Q3,Q4"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var table = parseFixture(t, test.html, DefaultOptions())
+			if len(table.Diagnostics) != 0 {
+				t.Fatalf("diagnostics = %v", table.Diagnostics)
+			}
+			if len(table.Columns) != len(test.headers) {
+				t.Fatalf("columns = %d, want %d", len(table.Columns), len(test.headers))
+			}
+			for i, column := range table.Columns {
+				if headers := cellTexts(column.Headers); headers != test.headers[i] {
+					t.Errorf("column %d headers = %q, want %q", i+1, headers, test.headers[i])
+				}
+				if groupHeaders := cellTexts(column.GroupHeaders); groupHeaders != test.groupHeaders[i] {
+					t.Errorf("column %d group headers = %q, want %q", i+1, groupHeaders, test.groupHeaders[i])
+				}
+				if column.Level != test.levels[i] {
+					t.Errorf("column %d level = %d, want %d", i+1, column.Level, test.levels[i])
+				}
+			}
+
+			var groups = []string{}
+			for _, group := range table.ColGroups {
+				groups = append(groups, fmt.Sprintf("%v %d %d-%d %s", group.Type, group.Level, group.Start, group.End, cellTexts(group.Headers)))
+			}
+			if reflect.DeepEqual(groups, test.groups) == false {
+				t.Errorf("groups = %q, want %q", groups, test.groups)
+			}
+		})
+	}
+}
+
+func TestHassumMode(t *testing.T) {
+	var withTotal = `<table%s>
+		<thead><tr><th>Item</th><th>Price</th></tr></thead>
+		<tbody><tr><th>A</th><td>1</td></tr><tr><th>B</th><td>2</td></tr></tbody>
+		<tbody><tr><th>Total</th><td>3</td></tr></tbody>
+	</table>`
+	var withoutTotal = `<table%s>
+		<thead><tr><th>Item</th><th>Price</th></tr></thead>
+		<tbody><tr><th>A</th><td>1</td></tr><tr><th>B</th><td>2</td></tr></tbody>
+		<tbody><tr><th>C</th><td>3</td></tr></tbody>
+	</table>`
+	var labelledTotal = `<table%s>
+		<thead><tr><th>Item</th><th>Price</th></tr></thead>
+		<tbody><tr><th colspan="2">Totals</th></tr><tr><th>Total</th><td>3</td></tr></tbody>
+	</table>`
+
+	var tests = []struct {
+		name       string
+		html       string
+		attributes string
+		mode       HassumMode
+		want       bool
+	}{
+		{"class mode without class", withTotal, "", HassumClass, false},
+		{"class mode with class", withTotal, ` class="hassum"`, HassumClass, true},
+		{"on", withoutTotal, "", HassumOn, true},
+		{"off with class", withTotal, ` class="hassum"`, HassumOff, false},
+		{"auto with a total row", withTotal, "", HassumAuto, true},
+		{"auto with a subtotal row", strings.Replace(withTotal, "Total", "Sub-total", 1), "", HassumAuto, true},
+		{"auto with a french total row", strings.Replace(withTotal, "Total", "Sous-total", 1), "", HassumAuto, true},
+		{"auto without total row", withoutTotal, "", HassumAuto, false},
+		{"auto with a header row", labelledTotal, "", HassumAuto, false},
+		{"auto with class", withoutTotal, ` class="hassum"`, HassumAuto, true},
+		{"attribute on", withoutTotal, ` data-hassum="on"`, HassumOff, true},
+		{"attribute off", withTotal, ` class="hassum" data-hassum="off"`, HassumOn, false},
+		{"attribute auto", withTotal, ` data-hassum="auto"`, HassumClass, true},
+		{"unknown attribute", withTotal, ` data-hassum="maybe"`, HassumOff, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(fmt.Sprintf(test.html, test.attributes)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := isHassumMode(doc.Find("table"), test.mode); got != test.want {
+				t.Errorf("isHassumMode = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHassumAutoSummaryRowGroup(t *testing.T) {
+	var opts = DefaultOptions()
+	opts.Hassum = HassumAuto
+	var table = parseFixture(t, `<table>
+		<thead><tr><th>Item</th><th>Price</th></tr></thead>
+		<tbody><tr><th>A</th><td>1</td></tr><tr><th>B</th><td>2</td></tr></tbody>
+		<tbody><tr><th>Total</th><td>3</td></tr></tbody>
+	</table>`, opts)
+
+	var types = []Type{}
+	for _, rowgroup := range table.RowGroups {
+		types = append(types, rowgroup.Type)
+	}
+	if reflect.DeepEqual(types, []Type{TypeData, TypeSummary}) == false {
+		t.Fatalf("row groups = %v, want data and summary", types)
+	}
+	if table.RowGroups[0].Summary != table.RowGroups[1] {
+		t.Error("the summary row group is not attached to the data row group")
+	}
+}
+
+func TestRowGroupTree(t *testing.T) {
+	var opts = DefaultOptions()
+	opts.Hassum = HassumOn
+	var table = parseFixture(t, `<table>
+		<thead><tr><th>Item</th><th>Price</th></tr></thead>
+		<tbody>
+			<tr><th colspan="2">Fruits</th></tr>
+			<tr><th>Apple</th><td>1</td></tr>
+			<tr><th>Pear</th><td>2</td></tr>
+		</tbody>
+		<tbody><tr><th>Subtotal</th><td>3</td></tr></tbody>
+		<tbody>
+			<tr><th colspan="2">Vegetables</th></tr>
+			<tr><th>Carrot</th><td>4</td></tr>
+		</tbody>
+		<tfoot><tr><th>Total</th><td>7</td></tr></tfoot>
+	</table>`, opts)
+	if len(table.Diagnostics) != 0 {
+		t.Fatalf("diagnostics = %v", table.Diagnostics)
+	}
+
+	var nodes = []string{}
+	for _, node := range table.RowGroups {
+		var parent = -1
+		for i, candidate := range table.RowGroups {
+			if candidate == node.Parent {
+				parent = i
+			}
+		}
+		nodes = append(nodes, fmt.Sprintf("%v %d rows=%d labels=%s parent=%d virtual=%v",
+			node.Type, node.Level, len(node.Rows), cellTexts(node.Labels), parent, node.Virtual()))
+	}
+	// The rows of a row group include his group header row
+	var want = []string{
+		"data 2 rows=3 labels=Fruits parent=-1 virtual=false",
+		"summary 2 rows=1 labels=Fruits parent=-1 virtual=false",
+		"data 2 rows=2 labels=Vegetables parent=-1 virtual=false",
+		"summary 0 rows=1 labels= parent=-1 virtual=false",
+	}
+	if reflect.DeepEqual(nodes, want) == false {
+		t.Fatalf("row groups =\n%s\nwant\n%s", strings.Join(nodes, "\n"), strings.Join(want, "\n"))
+	}
+	if table.RowGroups[0].Summary != table.RowGroups[1] {
+		t.Error("the subtotal is not the summary of the fruits")
+	}
+	if table.Footer != table.RowGroups[3] {
+		t.Error("the tfoot is not the footer")
+	}
+	if roots := table.RowGroupRoots(); len(roots) != 4 {
+		t.Errorf("roots = %d, want 4", len(roots))
+	}
+}
+
+func TestBuildRowGroupTree(t *testing.T) {
+	// Level 1 data group with two level 2 data groups, each with his summary, the summary of the level 1 group
+	// and the tfoot. A summary is also totaled by the summary at the lower level
+	var nodes = buildRowGroupTree([]RowGroup{
+		{etype: 2, level: 1},
+		{etype: 2, level: 2},
+		{etype: 3, level: 2},
+		{etype: 2, level: 2},
+		{etype: 3, level: 2},
+		{etype: 3, level: 1},
+		{etype: 3, level: 0},
+	})
+
+	var tests = []struct {
+		parent   int
+		summary  int
+		children int
+	}{
+		{parent: -1, summary: 5, children: 4},
+		{parent: 0, summary: 2, children: 0},
+		{parent: 0, summary: 5, children: 0},
+		{parent: 0, summary: 4, children: 0},
+		{parent: 0, summary: 5, children: 0},
+		{parent: -1, summary: 6, children: 0},
+		{parent: -1, summary: -1, children: 0},
+	}
+	var index = func(node *RowGroupNode) int {
+		for i, candidate := range nodes {
+			if candidate == node {
+				return i
+			}
+		}
+		return -1
+	}
+	for i, test := range tests {
+		if parent := index(nodes[i].Parent); parent != test.parent {
+			t.Errorf("node %d parent = %d, want %d", i, parent, test.parent)
+		}
+		if summary := index(nodes[i].Summary); summary != test.summary {
+			t.Errorf("node %d summary = %d, want %d", i, summary, test.summary)
+		}
+		if len(nodes[i].Children) != test.children {
+			t.Errorf("node %d children = %d, want %d", i, len(nodes[i].Children), test.children)
+		}
+	}
+}
+
+func TestKeyAndDescriptionCells(t *testing.T) {
+	var tests = []struct {
+		name         string
+		html         string
+		descriptions []string
+		keys         []string
+		describedBy  map[string]string
+	}{
+		{
+			name: "thead description row",
+			html: `<table>
+				<thead>
+					<tr><th>Item</th><th>Price</th></tr>
+					<tr><td id="d1">Name of the item</td><td>In dollars</td></tr>
+				</thead>
+				<tbody><tr><th>Apple</th><td>1</td></tr></tbody>
+			</table>`,
+			descriptions: []string{"Name of the item>Item", "In dollars>Price"},
+			keys:         []string{},
+			describedBy:  map[string]string{"Item": "d1", "Price": "tblparser-"},
+		},
+		{
+			name: "row group description row",
+			html: `<table>
+				<thead><tr><th>Item</th><th>Price</th></tr></thead>
+				<tbody>
+					<tr><th colspan="2">Fruits</th></tr>
+					<tr><td colspan="2" id="d2">Fresh fruits only</td></tr>
+					<tr><th>Apple</th><td>1</td></tr>
+				</tbody>
+			</table>`,
+			descriptions: []string{"Fresh fruits only>Fruits"},
+			keys:         []string{},
+			describedBy:  map[string]string{"Fruits": "d2"},
+		},
+		{
+			name: "key cell",
+			html: `<table>
+				<thead><tr><th>Key</th><th>Item</th><th>Price</th></tr></thead>
+				<tbody>
+					<tr><td id="k1">A</td><th>Apple</th><td>1</td></tr>
+					<tr><td id="k2">P</td><th>Pear</th><td>2</td></tr>
+				</tbody>
+			</table>`,
+			descriptions: []string{},
+			keys:         []string{"A>Apple", "P>Pear"},
+			describedBy:  map[string]string{"Apple": "k1", "Pear": "k2"},
+		},
+	}
+
+	var describes = func(cells []Cell) []string {
+		var result = []string{}
+		for _, cell := range cells {
+			result = append(result, cell.Text()+">"+cellTexts(cell.Describes()))
+		}
+		return result
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var opts = DefaultOptions()
+			opts.FixDescribedBy = true
+			var table = parseFixture(t, test.html, opts)
+			if len(table.Diagnostics) != 0 {
+				t.Fatalf("diagnostics = %v", table.Diagnostics)
+			}
+			if got := describes(table.DescriptionCells); reflect.DeepEqual(got, test.descriptions) == false {
+				t.Errorf("description cells = %q, want %q", got, test.descriptions)
+			}
+			if got := describes(table.KeyCells); reflect.DeepEqual(got, test.keys) == false {
+				t.Errorf("key cells = %q, want %q", got, test.keys)
+			}
+			for text, id := range test.describedBy {
+				var header = table.Selection.Find("th").FilterFunction(func(index int, th *goquery.Selection) bool {
+					return th.Text() == text
+				})
+				if value := header.AttrOr(DescribedByAttribute, ""); strings.HasPrefix(value, id) == false {
+					t.Errorf("%s aria-describedby = %q, want %q", text, value, id)
+				}
+			}
+		})
+	}
+}
+
+func TestLayoutCell(t *testing.T) {
+	var tests = []struct {
+		name    string
+		html    string
+		opts    func(opts *Options)
+		codes   []int
+		layouts int
+	}{
+		{
+			name: "empty corner cell",
+			html: `<table>
+				<thead><tr><td></td><th>Q1</th><th>Q2</th></tr></thead>
+				<tbody><tr><th>East</th><td>1</td><td>2</td></tr></tbody>
+			</table>`,
+			codes:   []int{},
+			layouts: 1,
+		},
+		{
+			name: "corner cell without row header",
+			html: `<table>
+				<thead><tr><td></td><th>Q1</th><th>Q2</th></tr></thead>
+				<tbody><tr><td>East</td><td>1</td><td>2</td></tr></tbody>
+			</table>`,
+			codes:   []int{},
+			layouts: 1,
+		},
+		{
+			name: "empty th corner cell",
+			html: `<table>
+				<thead><tr><th></th><th>Q1</th><th>Q2</th></tr></thead>
+				<tbody><tr><th>East</th><td>1</td><td>2</td></tr></tbody>
+			</table>`,
+			codes:   []int{38},
+			layouts: 1,
+		},
+		{
+			name: "corner cell spanned into the data columns",
+			html: `<table>
+				<thead><tr><td colspan="2"></td><th>Q2</th></tr></thead>
+				<tbody><tr><th>East</th><td>1</td><td>2</td></tr></tbody>
+			</table>`,
+			codes:   []int{39},
+			layouts: 1,
+		},
+		{
+			name: "ignored layout rules",
+			html: `<table>
+				<thead><tr><th></th><th>Q1</th><th>Q2</th></tr></thead>
+				<tbody><tr><th>East</th><td>1</td><td>2</td></tr></tbody>
+			</table>`,
+			opts:    func(opts *Options) { opts.IgnoreLayoutCell = true },
+			codes:   []int{},
+			layouts: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var opts = DefaultOptions()
+			if test.opts != nil {
+				test.opts(&opts)
+			}
+			var table = parseFixture(t, test.html, opts)
+			if codes := diagnosticCodes(table.Diagnostics); reflect.DeepEqual(codes, test.codes) == false {
+				t.Errorf("codes = %v, want %v", codes, test.codes)
+			}
+			if len(table.LayoutCells) != test.layouts {
+				t.Errorf("layout cells = %d, want %d", len(table.LayoutCells), test.layouts)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	var tests = []struct {
+		name  string
+		html  string
+		codes []int
+		err   bool
+	}{
+		{"no problem", `<table><tr><th>A</th><td>1</td></tr></table>`, []int{}, false},
+		{"info note only", `<table><tr><th>A</th><td>1</td></tr><script>var a = 1</script></table>`, []int{43}, false},
+		{"warning", `<table><thead><tr><th></th><th>Q1</th></tr></thead><tbody><tr><th>East</th><td>1</td></tr></tbody></table>`, []int{38}, true},
+		{"structure error", `<table><tr><th>A</th><td>1</td></tr><tr><th>B</th></tr></table>`, []int{16}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(test.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var opts = DefaultOptions()
+			opts.Lenient = true
+			table, err := Parse(doc.Find("table").First(), opts)
+			if codes := diagnosticCodes(table.Diagnostics); reflect.DeepEqual(codes, test.codes) == false {
+				t.Errorf("codes = %v, want %v", codes, test.codes)
+			}
+			if (err != nil) != test.err {
+				t.Errorf("err = %v, want an error %v", err, test.err)
+			}
+			if diagnostics, ok := err.(Diagnostics); ok == true {
+				for _, diag := range diagnostics {
+					if diag.Severity < SeverityWarning {
+						t.Errorf("the error have the %v diagnostic %v", diag.Severity, diag)
+					}
+				}
+			}
+		})
+	}
+}
+
+// The problems are suppressed by the disable comment before the table, the ignore attribute and the options
+func TestSuppressions(t *testing.T) {
+	// The table have the 36 problem on his second tfoot and the 29 problem on the spanned cell
+	var table = `<table%s><tr><th>A</th><th>B</th></tr><tbody><tr%s><td rowspan="3"%s>1</td><td>2</td></tr></tbody>` +
+		`<tfoot><tr><td>3</td><td>4</td></tr></tfoot><tfoot><tr><td>5</td></tr></tfoot></table>`
+
+	var tests = []struct {
+		name         string
+		comment      string
+		tableAttr    string
+		rowAttr      string
+		cellAttr     string
+		suppressions []string
+		reported     []int
+		suppressed   []int
+	}{
+		{"no suppression", "", "", "", "", nil, []int{36, 29}, []int{}},
+		{"comment without code", "<!-- tablevalidator-disable -->", "", "", "", nil, []int{}, []int{36, 29}},
+		{"comment with code", "<!-- tablevalidator-disable 29 -->\n", "", "", "", nil, []int{36}, []int{29}},
+		{"comment with code and id", "<!-- tablevalidator-disable multiple-tfoot, 29 -->", "", "", "", nil, []int{}, []int{36, 29}},
+		{"unrelated comment between", "<!-- tablevalidator-disable 29 --> <!-- generated -->", "", "", "", nil, []int{36}, []int{29}},
+		{"comment not before the table", "<!-- tablevalidator-disable --><p>Text</p>", "", "", "", nil, []int{36, 29}, []int{}},
+		{"table attribute without code", "", ` data-tablevalidator-ignore=""`, "", "", nil, []int{}, []int{36, 29}},
+		{"table attribute with code", "", ` data-tablevalidator-ignore="36"`, "", "", nil, []int{29}, []int{36}},
+		{"row attribute", "", "", ` data-tablevalidator-ignore="29"`, "", nil, []int{36}, []int{29}},
+		{"row attribute of an other element", "", "", ` data-tablevalidator-ignore="36"`, "", nil, []int{36, 29}, []int{}},
+		{"cell attribute with id", "", "", "", ` data-tablevalidator-ignore="rowspan-across-rowgroup"`, nil, []int{36}, []int{29}},
+		{"options with code", "", "", "", "", []string{"36"}, []int{29}, []int{36}},
+		{"options without code", "", "", "", "", []string{}, []int{}, []int{36, 29}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var source = "<p>Text</p>" + test.comment + fmt.Sprintf(table, test.tableAttr, test.rowAttr, test.cellAttr)
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(source))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var element = doc.Find("table").First()
+
+			// The suppressions of the options are set by table fingerprint
+			var opts = DefaultOptions()
+			if test.suppressions != nil {
+				opts.Suppressions = map[string][]string{Fingerprint(element): test.suppressions}
+			}
+
+			result, _ := Parse(element, opts)
+			if codes := diagnosticCodes(result.Diagnostics); reflect.DeepEqual(codes, test.reported) == false {
+				t.Errorf("reported = %v, want %v", codes, test.reported)
+			}
+			if codes := diagnosticCodes(result.Suppressed); reflect.DeepEqual(codes, test.suppressed) == false {
+				t.Errorf("suppressed = %v, want %v", codes, test.suppressed)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	var noHeader = `<table><tr><td>1</td><td>2</td></tr></table>`
+	var withCaption = `<table><caption>Prices</caption><tr><td>1</td><td>2</td></tr></table>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(noHeader + withCaption + noHeader + noHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fingerprints = []string{}
+	doc.Find("table").Each(func(index int, table *goquery.Selection) {
+		fingerprints = append(fingerprints, Fingerprint(table))
+	})
+
+	var base = fingerprints[0]
+	var want = []string{base, fingerprints[1], base + "-2", base + "-3"}
+	if reflect.DeepEqual(fingerprints, want) == false {
+		t.Fatalf("fingerprints = %v, want %v", fingerprints, want)
+	}
+	if fingerprints[1] == base {
+		t.Error("the caption does not change the fingerprint")
+	}
+
+	// The data rows do not change the fingerprint
+	doc, err = goquery.NewDocumentFromReader(strings.NewReader(`<table><tr><td>3</td><td>4</td></tr><tr><td>5</td><td>6</td></tr></table>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fingerprint := Fingerprint(doc.Find("table")); fingerprint != base {
+		t.Errorf("fingerprint = %s, want %s", fingerprint, base)
+	}
+
+	// The tables with only row headers are identified by their row header texts, an other table added
+	// before them does not change their fingerprint
+	var rowHeaders = `<table><tr><th>A</th><td>1</td></tr></table><table><tr><th>B</th><td>1</td></tr></table>`
+	doc, err = goquery.NewDocumentFromReader(strings.NewReader(rowHeaders))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var before = []string{Fingerprint(doc.Find("table").Eq(0)), Fingerprint(doc.Find("table").Eq(1))}
+	doc, err = goquery.NewDocumentFromReader(strings.NewReader(`<table><tr><th>C</th><td>1</td></tr></table>` + rowHeaders))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var after = []string{Fingerprint(doc.Find("table").Eq(1)), Fingerprint(doc.Find("table").Eq(2))}
+	if before[0] == before[1] || reflect.DeepEqual(before, after) == false {
+		t.Errorf("row header fingerprints = %v, after an added table %v", before, after)
+	}
+
+	// The fingerprint of an empty selection is empty
+	if fingerprint := Fingerprint(doc.Find("caption")); fingerprint != "" {
+		t.Errorf("fingerprint of an empty selection = %q, want empty", fingerprint)
+	}
+}
+
+func TestStreamFingerprint(t *testing.T) {
+	var source = `<table><tr><td>1</td></tr></table><p>text</p><table><tr><td>2</td></tr></table>`
+	var stream = []string{}
+	var err = StreamReader(context.Background(), strings.NewReader(source), DefaultOptions(), func(result Result) error {
+		stream = append(stream, result.Table.Fingerprint)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ParseString(source, DefaultOptions(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document = []string{}
+	for _, result := range results {
+		document = append(document, result.Table.Fingerprint)
+	}
+
+	if reflect.DeepEqual(stream, document) == false || stream[0] == stream[1] {
+		t.Errorf("stream fingerprints = %v, document fingerprints = %v", stream, document)
+	}
+}
+
+// The function of the stream is called without the lock of the parser, it can parse other tables
+func TestStreamParseInCallback(t *testing.T) {
+	var source = `<table><tr><td>1</td></tr></table><table><tr><td>2</td></tr></table>`
+	var count = 0
+	var err = StreamReader(context.Background(), strings.NewReader(source), DefaultOptions(), func(result Result) error {
+		results, err := ParseString(source, DefaultOptions(), "")
+		count += len(results)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Errorf("tables parsed in the callback = %d, want 4", count)
+	}
+}
+
+// The streamed tables have the same problems and fingerprints as in the document
+func TestStreamDocument(t *testing.T) {
+	var tests = []struct {
+		name    string
+		source  string
+		lenient bool
+	}{
+		{"rows", `<table><caption>Fruits</caption><thead><tr><th>Name</th><th>Price</th></tr></thead><tbody><tr><th>Apple</th><td>1</td></tr><tr><th>Pear</th><td>2</td></tr></tbody></table>`, false},
+		{"implied rows", `<table><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr><td>3<td>4</table>`, false},
+		{"row groups", `<table><tr><th>A</th><th>B</th></tr><tbody><tr><th>x</th><td>1</td></tr></tbody><tbody class="x"><tr><th>y</th><td>2</td></tr></tbody></table>`, false},
+		{"tfoot before tbody", `<table><thead><tr><th>A</th><th>B</th></tr></thead><tfoot><tr><th>Total</th><td>3</td></tr></tfoot><tfoot><tr><td>x</td></tr></tfoot><tbody><tr><th>x</th><td>1</td></tr></tbody></table>`, false},
+		{"row span", `<table><tr><th>A</th><th>B</th></tr><tbody><tr><td rowspan="3">1</td><td>2</td></tr><tr><td>3</td></tr></tbody><tbody><tr><td>4</td><td>5</td></tr></tbody></table>`, false},
+		{"structure error", `<table><tr><th>A</th><th>B</th></tr><tbody><tr><td>1</td><td>2</td></tr><style>x</style><tr><th>z</th><td>3</td></tr></tbody></table>`, false},
+		{"nested", `<table id="outer"><tr><th>A</th></tr><tr><td><table><tr><th>B</th></tr><tr><td>1</td></tr></table></td></tr><tr><td><table><tr><th>B</th></tr><tr><td>2</td></tr></table></td></tr></table><table><tr><th>B</th></tr><tr><td>3</td></tr></table>`, false},
+		{"disable comment", `<p>Text</p><!-- tablevalidator-disable 29 --> <table><tr><th>A</th><th>B</th></tr><tbody><tr><td rowspan="3">1</td><td>2</td></tr></tbody></table>`, false},
+		{"lenient", `<table><tr><th>A</th><th>B</th></tr><tbody><script>x</script><tr><td>1</td><td>2</td></tr><template><tr><td>3</td></tr></template></tbody></table>`, true},
+	}
+
+	for _, test := range tests {
+		var opts = DefaultOptions()
+		opts.Lenient = test.lenient
+
+		var stream = []string{}
+		var err = StreamReader(context.Background(), strings.NewReader(test.source), opts, func(result Result) error {
+			stream = append(stream, fmt.Sprintf("%d %s %v %v", result.Index, result.Table.Fingerprint, diagnosticCodes(result.Table.Diagnostics), diagnosticCodes(result.Table.Suppressed)))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		results, err := ParseString(test.source, opts, "")
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var document = []string{}
+		for _, result := range results {
+			document = append(document, fmt.Sprintf("%d %s %v %v", result.Index, result.Table.Fingerprint, diagnosticCodes(result.Table.Diagnostics), diagnosticCodes(result.Table.Suppressed)))
+		}
+
+		if reflect.DeepEqual(stream, document) == false {
+			t.Errorf("%s: stream tables = %v, document tables = %v", test.name, stream, document)
+		}
+	}
+}
+
+// The streamed rows are all validated, the MaxRows limit only count the kept rows, and they are not kept
+func TestStreamRows(t *testing.T) {
+	var source = "<table><thead><tr><th>A</th><th>B</th></tr></thead><tbody>" + strings.Repeat("<tr><th>x</th><td>1</td></tr>", 50) +
+		`<tr><th>y</th><td colspan="2">1</td></tr></tbody></table>`
+	var opts = DefaultOptions()
+	opts.MaxRows = 2
+
+	var codes []int
+	var rows = 0
+	var err = StreamReader(context.Background(), strings.NewReader(source), opts, func(result Result) error {
+		codes = diagnosticCodes(result.Table.Diagnostics)
+		rows = result.Table.Selection.Find("tr").Length()
+		if len(result.Table.Grid()) != 0 {
+			t.Errorf("the grid of a streamed table has %d rows, want 0", len(result.Table.Grid()))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ParseString(source, DefaultOptions(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := diagnosticCodes(results[0].Table.Diagnostics); reflect.DeepEqual(codes, want) == false || len(want) == 0 {
+		t.Errorf("stream diagnostics = %v, want %v", codes, want)
+	}
+	if rows != 1 {
+		t.Errorf("the streamed table keep %d rows, want the thead row", rows)
+	}
+
+	// The columns of each streamed row are limited
+	opts.MaxColumns = 2
+	codes = nil
+	err = StreamReader(context.Background(), strings.NewReader(source), opts, func(result Result) error {
+		codes = diagnosticCodes(result.Table.Diagnostics)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(codes, []int{41}) == false {
+		t.Errorf("stream diagnostics with MaxColumns = %v, want [41]", codes)
+	}
+}
+
+// A table over a limit is reported with the limit and the counted value, and it is not parsed
+func TestLimits(t *testing.T) {
+	var tests = []struct {
+		name  string
+		html  string
+		limit func(opts *Options)
+		code  int
+		value string
+	}{
+		{"under the limits", `<table><colgroup span="2"></colgroup><tr><td colspan="2">1</td></tr><tr><td>2</td><td>3</td></tr></table>`, func(opts *Options) { opts.MaxColumns, opts.MaxSlots = 2, 4 }, 0, ""},
+		{"rows", `<table><tr><td>1</td></tr><tr><td>2</td></tr><tr><td>3</td></tr></table>`, func(opts *Options) { opts.MaxRows = 2 }, 40, "3"},
+		{"colgroup span", `<table><colgroup span="3"></colgroup><tr><td>1</td></tr></table>`, func(opts *Options) { opts.MaxColumns = 2 }, 41, "3"},
+		{"col span", `<table><colgroup><col span="2"><col></colgroup><tr><td>1</td></tr></table>`, func(opts *Options) { opts.MaxColumns = 2 }, 41, "3"},
+		{"colspan", `<table><tr><td colspan="2">1</td><td>2</td></tr></table>`, func(opts *Options) { opts.MaxColumns = 2 }, 41, "3"},
+		{"capped colspan", `<table><tr><td colspan="99999999999">1</td></tr></table>`, func(opts *Options) {}, 41, "1073741824"},
+		{"slots", `<table><tr><td colspan="2" rowspan="3">1</td></tr><tr></tr><tr></tr></table>`, func(opts *Options) { opts.MaxSlots = 4 }, 42, "6"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var opts = DefaultOptions()
+			test.limit(&opts)
+			var table = parseFixture(t, test.html, opts)
+
+			var codes = []string{}
+			for _, diag := range table.Diagnostics {
+				codes = append(codes, fmt.Sprintf("%d %s", diag.Rule.Code, diag.Args["value"]))
+			}
+			var want = []string{}
+			if test.code != 0 {
+				want = append(want, fmt.Sprintf("%d %s", test.code, test.value))
+			}
+			if reflect.DeepEqual(codes, want) == false {
+				t.Errorf("diagnostics = %v, want %v", codes, want)
+			}
+			if test.code != 0 && len(table.Grid()) != 0 {
+				t.Errorf("the grid of a table over a limit has %d rows, want 0", len(table.Grid()))
+			}
+		})
+	}
+}
+
+// countdownContext is canceled after a number of checks
+type countdownContext struct {
+	context.Context
+	checks int
+}
+
+func (ctx *countdownContext) Err() error {
+	ctx.checks--
+	if ctx.checks < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+// The parsing stop with the error of a canceled context or of an exceeded deadline
+func TestParseContextCanceled(t *testing.T) {
+	var source = `<table><tr><th>A</th><td>1</td></tr><tr><th>B</th><td>2</td></tr></table><table><tr><td>3</td></tr></table>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	var tests = []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{"canceled", canceled, context.Canceled},
+		{"deadline exceeded", expired, context.DeadlineExceeded},
+		// The context is checked before the table and before each row, it is canceled on the second row
+		{"canceled between the rows", &countdownContext{Context: context.Background(), checks: 2}, context.Canceled},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table, err := ParseContext(test.ctx, doc.Find("table").First(), DefaultOptions())
+			if err != test.want || table == nil {
+				t.Errorf("err = %v, table = %v, want %v and a table", err, table, test.want)
+			}
+		})
+	}
+
+	// The documents stop before the next table, the tables already parsed are returned
+	results, err := ParseSelectionContext(&countdownContext{Context: context.Background(), checks: 5}, doc.Selection, DefaultOptions(), "")
+	if err != context.Canceled || len(results) != 1 {
+		t.Errorf("document: err = %v, %d results, want %v and 1 result", err, len(results), context.Canceled)
+	}
+
+	// The stream stop when the context is canceled by the function
+	ctx, cancelStream := context.WithCancel(context.Background())
+	defer cancelStream()
+	var count = 0
+	err = StreamReader(ctx, strings.NewReader(source), DefaultOptions(), func(result Result) error {
+		count++
+		cancelStream()
+		return nil
+	})
+	if err != context.Canceled || count != 1 {
+		t.Errorf("stream: err = %v, %d tables, want %v and 1 table", err, count, context.Canceled)
+	}
+}
+
+// The tables are found in the root and in the elements matched by the selector, in the document order
+func TestParseNode(t *testing.T) {
+	var source = `<table id="t1"><tr><td>1</td></tr></table>` +
+		`<div class="report"><table id="t2"><tr><td><table id="t3"><tr><td>3</td></tr></table></td></tr></table></div>` +
+		`<table id="t4" class="report"><tr><td>4</td></tr></table>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name     string
+		root     *goquery.Selection
+		selector string
+		ids      []string
+	}{
+		{"all the tables", doc.Selection, "", []string{"t1", "t2", "t3", "t4"}},
+		{"container and table", doc.Selection, ".report", []string{"t2", "t3", "t4"}},
+		{"selector in document order", doc.Selection, "#t4, #t3, #t1", []string{"t1", "t3", "t4"}},
+		{"table root", doc.Find("#t2"), "", []string{"t2", "t3"}},
+		{"table root matched by the selector", doc.Find("#t4"), ".report", []string{"t4"}},
+		{"selector without table", doc.Selection, "p", []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := ParseNode(test.root.Nodes[0], DefaultOptions(), test.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids = []string{}
+			for i, result := range results {
+				if result.Index != i+1 {
+					t.Errorf("index of the table %d = %d", i+1, result.Index)
+				}
+				ids = append(ids, result.Table.Selection.AttrOr("id", ""))
+			}
+			if reflect.DeepEqual(ids, test.ids) == false {
+				t.Errorf("tables = %v, want %v", ids, test.ids)
+			}
+		})
+	}
+
+	// An invalid selector is returned as an error instead of a panic
+	if _, err := ParseNode(doc.Nodes[0], DefaultOptions(), "table["); err == nil {
+		t.Error("invalid selector: no error")
+	}
+}
+
+// The adapters remove the parts that are not html and keep the lines, the problems are on the lines of the source file
+func TestAdapters(t *testing.T) {
+	var tests = []struct {
+		name    string
+		adapter Adapter
+		source  string
+		want    []string
+	}{
+		{"markdown", MarkdownAdapter, "# Title\n\n```html\n<table><tr><td>x</td></tr></table>\n```\n\n" +
+			"    <table><tr><td>x</td></tr></table>\n\n" +
+			"<table>\n<tr><th>A</th><td>1</td></tr>\n\n<tr><th>B</th></tr>\n</table>\n\nText <table></table>\n",
+			[]string{"1 tables", "16 12"}},
+		{"go template", GoTemplateAdapter, "{{/* <table><tr><td>x</td></tr></table> */}}\n{{define \"t\"}}\n" +
+			"<table {{if .ID}}id=\"{{.ID}}\"{{end}}>\n{{range .Rows}}\n<tr><th>A</th><td>{{.Value}}</td></tr>\n{{end}}\n" +
+			"<tr {{if .X}}class=\"x\"{{end}}><th>B</th></tr>\n</table>\n{{end}}\n",
+			[]string{"1 tables", "16 7"}},
+		{"handlebars", HandlebarsAdapter, "{{!-- <table><tr><td>x</td></tr></table> --}}\n{{! a comment }}\n" +
+			"<table {{#if id}}id=\"{{id}}\"{{/if}}>\n{{#each rows}}\n<tr><th>A</th><td>{{{value}}}</td></tr>\n{{else}}\n" +
+			"<tr><th>A</th><td>-</td></tr>\n{{/each}}\n<tr><th>B</th></tr>\n</table>\n",
+			[]string{"1 tables", "16 9"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var adapted = test.adapter(test.source)
+			if len(adapted) != len(test.source) || strings.Count(adapted, "\n") != strings.Count(test.source, "\n") {
+				t.Fatalf("the adapted source does not keep the positions:\n%s", adapted)
+			}
+
+			sourceMap, root, err := NewSourceMapReader(strings.NewReader(adapted))
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := ParseNode(root, DefaultOptions(), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The tables of the code blocks and of the comments are removed
+			var lines = []string{fmt.Sprintf("%d tables", len(results))}
+			for _, result := range results {
+				for _, diag := range result.Table.Diagnostics {
+					var start, _, _ = sourceMap.NearestStartTag(diag.Selection)
+					lines = append(lines, fmt.Sprintf("%d %d", diag.Rule.Code, start.Line))
+				}
+			}
+			if reflect.DeepEqual(lines, test.want) == false {
+				t.Errorf("problems = %v, want %v\nadapted source:\n%s", lines, test.want, adapted)
+			}
+		})
+	}
+}
+
+// The caption element is split in the caption text and the descriptions
+func TestSplitCaption(t *testing.T) {
+	var tests = []struct {
+		name         string
+		caption      string
+		text         string
+		descriptions []string
+	}{
+		{"text", `<caption>Prices</caption>`, "Prices", []string{}},
+		{"comments and white spaces", `<caption><!-- c --> <strong>Prices</strong> <!-- d --> in 2020</caption>`, "Prices", []string{"in 2020"}},
+		{"br in the caption", `<caption>Prices<br>in dollars</caption>`, "Prices in dollars", []string{}},
+		{"br in the description", `<caption><strong>Prices</strong><br>in dollars<br>per unit</caption>`, "Prices", []string{"in dollars per unit"}},
+		{"nested strong", `<caption><strong>Prices <strong>2020</strong></strong><p>Desc</p></caption>`, "Prices 2020", []string{"Desc"}},
+		{"strong inside an inline element", `<caption><span><strong>A</strong></span> rest</caption>`, "A rest", []string{}},
+		{"block element first", `<caption><p>Prices</p><p>Desc</p></caption>`, "Prices", []string{"Desc"}},
+		{"inline content before a block", `<caption>Prices <em>2020</em><p>Desc</p> tail</caption>`, "Prices 2020", []string{"Desc", "tail"}},
+		{"details and summary", `<caption><details><summary>Prices</summary><p>Detail</p>more</details><p>After</p></caption>`, "Prices", []string{"Detail", "more", "After"}},
+		{"details without summary", `<caption><details>Prices</details><p>After</p></caption>`, "Prices", []string{"After"}},
+		{"empty caption", `<caption><!-- x --> <br><span> </span></caption>`, "", []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var table = parseFixture(t, "<table>"+test.caption+"<tr><td>1</td></tr></table>", DefaultOptions())
+			if table.Caption.Text != test.text || reflect.DeepEqual(table.Caption.Descriptions, test.descriptions) == false {
+				t.Errorf("caption = %q %q, want %q %q", table.Caption.Text, table.Caption.Descriptions, test.text, test.descriptions)
+			}
+		})
+	}
+
+	// The elements referenced by aria-describedby on the table and on the caption follow the caption descriptions
+	var table = parseFixture(t, `<p id="d1">Note <b>one</b></p><p id="d2">Note two</p>`+
+		`<table aria-describedby="d1 missing"><caption aria-describedby="d2">Prices<p>Desc</p></caption><tr><td>1</td></tr></table>`, DefaultOptions())
+	if want := []string{"Desc", "Note one", "Note two"}; reflect.DeepEqual(table.Caption.Descriptions, want) == false {
+		t.Errorf("descriptions = %q, want %q", table.Caption.Descriptions, want)
+	}
+}
+
+// The accessible name come from aria-labelledby, aria-label, the caption and the title, in this order
+func TestAccessibleName(t *testing.T) {
+	var tests = []struct {
+		name        string
+		attrs       string
+		caption     string
+		want        string
+		source      string
+		description string
+	}{
+		{"aria-labelledby first", `aria-labelledby="l1 l2" aria-label="Label" title="Title"`, "<caption>Caption</caption>", "Heading Sub heading", "aria-labelledby", "Title"},
+		{"aria-labelledby without element", `aria-labelledby="missing" aria-label="Label"`, "<caption>Caption</caption>", "Label", "aria-label", ""},
+		{"aria-label before the caption", `aria-label=" Label  text "`, "<caption>Caption</caption>", "Label text", "aria-label", ""},
+		{"whole caption", `title="Title"`, "<caption>Caption<p>Desc</p></caption>", "Caption Desc", "caption", "Title"},
+		{"empty caption", `title="Title" summary="Summary"`, "<caption> <!-- x --> </caption>", "Title", "title", "Summary"},
+		{"no name", `summary="Summary"`, "", "", "", "Summary"},
+		{"aria-describedby first", `aria-describedby="d" aria-description="Description" title="Title"`, "<caption>Caption</caption>", "Caption", "caption", "Details"},
+		{"aria-description before the title", `aria-description="Description" title="Title"`, "<caption>Caption</caption>", "Caption", "caption", "Description"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var source = `<h2 id="l1">Heading</h2><p id="l2">Sub <em>heading</em></p><p id="d">Details</p>` +
+				"<table " + test.attrs + ">" + test.caption + "<tr><td>1</td></tr></table>"
+			var caption = parseFixture(t, source, DefaultOptions()).Caption
+			if caption.AccessibleName != test.want || caption.NameSource != test.source || caption.AccessibleDescription != test.description {
+				t.Errorf("name = %q from %q, description = %q, want %q from %q and %q",
+					caption.AccessibleName, caption.NameSource, caption.AccessibleDescription, test.want, test.source, test.description)
+			}
+		})
+	}
+}
+
+func TestTechniquesOfRules(t *testing.T) {
+	// Each technique is used by a rule, and each technique of a rule is known
+	var used = map[string]bool{}
+	for _, rule := range Rules() {
+		for _, id := range rule.Techniques {
+			if _, ok := TechniqueByID(id); ok == false {
+				t.Errorf("unknown technique %s of the rule %s", id, rule.ID)
+			}
+			used[id] = true
+		}
+	}
+	for _, technique := range Techniques() {
+		if used[technique.ID] == false {
+			t.Errorf("the technique %s is not used by a rule", technique.ID)
+		}
+	}
+}
+
+func TestRegisterCatalog(t *testing.T) {
+	var saved = map[string]Catalog{}
+	for lang, catalog := range catalogs {
+		saved[lang] = catalog
+	}
+	defer func() {
+		catalogs = saved
+	}()
+
+	RegisterCatalog("en", Catalog{"row-width": "Bad row"})
+	RegisterCatalog("de", Catalog{"tfoot-row-width": "Falsche Breite {width}"})
+
+	var tests = []struct {
+		lang string
+		code int
+		want string
+	}{
+		{"en", 16, "Bad row"},
+		{"en", 37, "The tfoot row do not have the same width as the table body, it have 2 cells instead of {expected}"},
+		{"fr", 16, "La rangée n'a pas la bonne largeur, elle a 2 cellules au lieu de {expected}"},
+		{"de", 37, "Falsche Breite 2"},
+		{"de", 16, "Bad row"},
+	}
+	for _, test := range tests {
+		options = DefaultOptions()
+		options.Lang = test.lang
+		var rule, _ = RuleByCode(test.code)
+		if message := formatMessage(rule, map[string]string{"width": "2"}); message != test.want {
+			t.Errorf("%s message of %d = %q, want %q", test.lang, test.code, message, test.want)
+		}
+	}
+	options = DefaultOptions()
+}
+
+// gridText write the grid with the text of the cell of each slot, and "-" for an empty slot
+func gridText(grid [][]Cell) []string {
+	var lines = []string{}
+	for _, line := range grid {
+		var texts = []string{}
+		for _, slot := range line {
+			if slot.Selection() == nil {
+				texts = append(texts, "-")
+			} else {
+				texts = append(texts, slot.Text()+":"+slot.Type().String())
+			}
+		}
+		lines = append(lines, strings.Join(texts, " "))
+	}
+	return lines
+}
+
+func TestGrid(t *testing.T) {
+	var tests = []struct {
+		name  string
+		html  string
+		codes []int
+		grid  []string
+	}{
+		{
+			name: "spanned cells",
+			html: `<table>
+				<thead><tr><td></td><th colspan="2">Sales</th></tr></thead>
+				<tbody>
+					<tr><th rowspan="2">East</th><td>1</td><td>2</td></tr>
+					<tr><td>3</td><td>4</td></tr>
+				</tbody>
+			</table>`,
+			codes: []int{},
+			grid: []string{
+				":layout Sales:header Sales:header",
+				"East:header 1:data 2:data",
+				"East:header 3:data 4:data",
+			},
+		},
+		{
+			name: "row wider than the table",
+			html: `<table>
+				<tr><th>A</th><td>1</td></tr>
+				<tr><th>B</th><td>2</td><td>3</td></tr>
+				<tr><th>C</th><td>4</td></tr>
+			</table>`,
+			codes: []int{16},
+			grid: []string{
+				"A:header 1:data",
+				"B:unknown 2:unknown 3:unknown",
+				"C:unknown 4:unknown",
+			},
+		},
+		{
+			name: "rowspan beyond the row group",
+			html: `<table>
+				<tbody><tr><th>A</th><td rowspan="5">1</td></tr><tr><th>B</th></tr></tbody>
+				<tbody><tr><th>C</th><td>2</td></tr></tbody>
+			</table>`,
+			codes: []int{29},
+			grid: []string{
+				"A:header 1:data",
+				"B:header 1:data",
+				"C:unknown 2:unknown",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var table = parseFixture(t, test.html, DefaultOptions())
+			if codes := diagnosticCodes(table.Diagnostics); reflect.DeepEqual(codes, test.codes) == false {
+				t.Errorf("codes = %v, want %v", codes, test.codes)
+			}
+			if table.grid != nil {
+				t.Error("the grid is built before it is used")
+			}
+			if grid := gridText(table.Grid()); reflect.DeepEqual(grid, test.grid) == false {
+				t.Errorf("grid =\n%s\nwant\n%s", strings.Join(grid, "\n"), strings.Join(test.grid, "\n"))
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	var table = parseFixture(t, `<table>
+		<thead>
+			<tr><td rowspan="2"></td><th colspan="2">Sales</th><th rowspan="2">Total</th></tr>
+			<tr><th>Q1</th><th>Q2</th></tr>
+		</thead>
+		<tbody>
+			<tr><th colspan="4">Fruits</th></tr>
+			<tr><th rowspan="2">Apple</th><td>1</td><td>2</td><td>3</td></tr>
+			<tr><td>4</td><td>5</td><td>6</td></tr>
+		</tbody>
+	</table>`, DefaultOptions())
+	if codes := diagnosticCodes(table.Diagnostics); len(codes) != 0 {
+		t.Fatalf("codes = %v, want none", codes)
+	}
+
+	var headers = map[string]string{}
+	for _, line := range table.Grid() {
+		for _, slot := range line {
+			if slot.Selection() != nil && slot.Text() != "" {
+				headers[slot.Text()] = cellTexts(table.Headers(slot))
+			}
+		}
+	}
+	var want = map[string]string{
+		"Sales":  "",
+		"Q1":     "Sales",
+		"Q2":     "Sales",
+		"Total":  "",
+		"Fruits": "",
+		"Apple":  "Fruits",
+		"1":      "Sales,Q1,Fruits,Apple",
+		"3":      "Total,Fruits,Apple",
+		"5":      "Sales,Q2,Fruits,Apple",
+		"4":      "Sales,Q1,Fruits,Apple",
+		"6":      "Total,Fruits,Apple",
+	}
+	for text, expected := range want {
+		if headers[text] != expected {
+			t.Errorf("headers of %s = %q, want %q", text, headers[text], expected)
+		}
+	}
+}
+
+// The tags are found with an implied tbody and with more tr elements than tr tags, the implied elements
+// do not have a tag
+func TestSourceMap(t *testing.T) {
+	var source = "<p>text</p>\n<table>\n<tr><td>1</td></tr>\n</table>\n<TABLE id=\"b\"><TBODY><tr><td>2\n<tr><td>3</table>"
+	sourceMap, root, err := NewSourceMapReader(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tags = []string{}
+	goquery.NewDocumentFromNode(root).Find("table, tbody, tr, td").Each(func(index int, elem *goquery.Selection) {
+		var start, end, ok = sourceMap.StartTag(elem)
+		if ok == false {
+			tags = append(tags, goquery.NodeName(elem)+" -")
+			return
+		}
+		tags = append(tags, fmt.Sprintf("%s %d:%d %s", goquery.NodeName(elem), start.Line, start.Column, source[start.Offset:end.Offset]))
+	})
+	var want = []string{
+		"table 2:1 <table>",
+		"tbody -",
+		"tr 3:1 <tr>",
+		"td 3:5 <td>",
+		"table 5:1 <TABLE id=\"b\">",
+		"tbody 5:15 <TBODY>",
+		"tr 5:22 <tr>",
+		"td 5:26 <td>",
+		"tr 6:1 <tr>",
+		"td 6:5 <td>",
+	}
+	if reflect.DeepEqual(tags, want) == false {
+		t.Errorf("tags =\n%s\nwant\n%s", strings.Join(tags, "\n"), strings.Join(want, "\n"))
+	}
+
+	if start, end, ok := sourceMap.Element(goquery.NewDocumentFromNode(root).Find("table").First()); ok == false ||
+		source[start.Offset:end.Offset] != "<table>\n<tr><td>1</td></tr>\n</table>" {
+		t.Errorf("element of the first table = %v %v %v", start, end, ok)
+	}
+}
+
+// The lenient mode ignore the elements that the html parser keep in the table structure with info notes (code 43),
+// the same tables fail in the strict mode. The wrapper elements and the text of the word processors are moved
+// before the table by the html parser, the two modes give the same result for them
+func TestLenient(t *testing.T) {
+	var tests = []struct {
+		name    string
+		html    string
+		strict  []int
+		lenient []int
+		notes   []string
+	}{
+		{
+			name:    "comments and white spaces",
+			html:    "<table>\n<!-- header -->\n<tr> <th>A</th> <!-- value --> <td>1</td> </tr>\n</table>",
+			strict:  []int{},
+			lenient: []int{},
+			notes:   []string{},
+		},
+		{
+			name:    "script and template",
+			html:    `<table><script>var a = 1</script><tr><th>A</th><td>1</td><template><td>2</td></template></tr></table>`,
+			strict:  []int{30},
+			lenient: []int{43, 43},
+			notes:   []string{"script", "template"},
+		},
+		{
+			name:    "style in the row group",
+			html:    `<table><tbody><style>td { color: red }</style><tr><th>A</th><td>1</td></tr></tbody></table>`,
+			strict:  []int{27},
+			lenient: []int{43},
+			notes:   []string{"style"},
+		},
+		{
+			name:    "form of an email client",
+			html:    `<table><tr><th>A</th><td>1</td><form action="/"><input type="hidden" name="id" value="1"></form></tr></table>`,
+			strict:  []int{15},
+			lenient: []int{43, 43},
+			notes:   []string{"form", "input"},
+		},
+		{
+			name:    "wrappers and text of a word processor",
+			html:    `<table><font><tr><th>A</th><o:p></o:p><td>1</td>text</tr></font></table>`,
+			strict:  []int{},
+			lenient: []int{},
+			notes:   []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, lenient := range []bool{false, true} {
+				var opts = DefaultOptions()
+				opts.Lenient = lenient
+				var table = parseFixture(t, test.html, opts)
+
+				var want = test.strict
+				if lenient == true {
+					want = test.lenient
+				}
+				if codes := diagnosticCodes(table.Diagnostics); reflect.DeepEqual(codes, want) == false {
+					t.Errorf("lenient %v: codes = %v, want %v", lenient, codes, want)
+				}
+				if lenient == false {
+					continue
+				}
+
+				var notes = []string{}
+				for _, diag := range table.Diagnostics {
+					notes = append(notes, diag.Args["element"])
+					if diag.Severity != SeverityInfo {
+						t.Errorf("the note %v is not an info", diag)
+					}
+				}
+				if reflect.DeepEqual(notes, test.notes) == false {
+					t.Errorf("notes = %v, want %v", notes, test.notes)
+				}
+			}
+		})
+	}
+}
+
+// An empty selection is parsed as an empty table, without panic
+func TestParseEmptySelection(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<p>no table</p>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var empty = doc.Find("table")
+	if err := Init(empty); err != nil {
+		t.Errorf("Init = %v, want nil", err)
+	}
+	table, err := Parse(empty, DefaultOptions())
+	if err != nil || table == nil {
+		t.Fatalf("Parse = %v, %v", table, err)
+	}
+	if table.Caption.Element != nil || len(table.Caption.Text) != 0 || len(table.Caption.Descriptions) != 0 {
+		t.Errorf("caption = %+v, want an empty caption", table.Caption)
+	}
+	if caption := TableCaption(empty); caption.Element != nil || len(caption.AccessibleName) != 0 {
+		t.Errorf("TableCaption = %+v, want an empty caption", caption)
+	}
+}
@@ -0,0 +1,98 @@
+package tableparser
+
+// Criterion is a WCAG 2.x success criterion
+type Criterion struct {
+	// ID is the number of the success criterion, like "1.3.1"
+	ID string
+	// Name is the short name of the success criterion
+	Name string
+	// Level is the conformance level: "A", "AA" or "AAA"
+	Level string
+	// URL is the understanding document of the success criterion
+	URL string
+}
+
+// Technique is a WCAG sufficient technique (H) or a failure (F)
+type Technique struct {
+	// ID is the technique number, like "H43" or "F90"
+	ID string
+	// Title is the title of the technique
+	Title string
+	// URL is the technique document
+	URL string
+}
+
+const wcagUnderstanding = "https://www.w3.org/WAI/WCAG21/Understanding/"
+const wcagTechniques = "https://www.w3.org/WAI/WCAG21/Techniques/"
+
+var criteria = []Criterion{
+	{
+		ID:    "1.3.1",
+		Name:  "Info and Relationships",
+		Level: "A",
+		URL:   wcagUnderstanding + "info-and-relationships",
+	},
+}
+
+var techniques = []Technique{
+	{
+		ID:    "H43",
+		Title: "Using id and headers attributes to associate data cells with header cells in data tables",
+		URL:   wcagTechniques + "html/H43",
+	},
+	{
+		ID:    "H51",
+		Title: "Using table markup to present tabular information",
+		URL:   wcagTechniques + "html/H51",
+	},
+	{
+		ID:    "H63",
+		Title: "Using the scope attribute to associate header cells and data cells in data tables",
+		URL:   wcagTechniques + "html/H63",
+	},
+	{
+		ID:    "F46",
+		Title: "Failure due to using th elements, caption elements, or non-empty summary attributes in layout tables",
+		URL:   wcagTechniques + "failures/F46",
+	},
+	{
+		ID:    "F90",
+		Title: "Failure for incorrectly associating table headers and content via the headers and id attributes",
+		URL:   wcagTechniques + "failures/F90",
+	},
+	{
+		ID:    "F91",
+		Title: "Failure for not correctly marking up table headers",
+		URL:   wcagTechniques + "failures/F91",
+	},
+}
+
+// Criteria return the WCAG success criteria checked by the rules
+func Criteria() []Criterion {
+	return append([]Criterion{}, criteria...)
+}
+
+// CriterionByID return the success criterion with the id, ok is false when the id is unknown
+func CriterionByID(id string) (criterion Criterion, ok bool) {
+	for _, criterion := range criteria {
+		if criterion.ID == id {
+			return criterion, true
+		}
+	}
+	return Criterion{ID: id}, false
+}
+
+// Techniques return the WCAG techniques and failures referenced by the rules
+func Techniques() []Technique {
+	return append([]Technique{}, techniques...)
+}
+
+// TechniqueByID return the technique with the id, ok is false when the id is unknown
+func TechniqueByID(id string) (technique Technique, ok bool) {
+	for _, technique := range techniques {
+		if technique.ID == id {
+			return technique, true
+		}
+	}
+	return Technique{ID: id}, false
+}
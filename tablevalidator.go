@@ -1,37 +1,301 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
-	"github.com/PuerkitoBio/goquery"
-
 	"github.com/quycao/gotablevalidator/tableparser"
 )
 
+// Default file validated when no file is given
+const defaultFilePath = "table.html"
+
+// TableResult is the validation result of a table
+type TableResult struct {
+	Index int
+	// Fingerprint is the stable identifier of the table, see tableparser.Fingerprint
+	Fingerprint string
+	// Caption is the caption and the accessible name of the table, see tableparser.TableCaption
+	Caption     tableparser.Caption
+	Diagnostics tableparser.Diagnostics
+	Suppressed  tableparser.Diagnostics
+	// Table is the parsed structure of the table
+	Table *tableparser.Table
+}
+
+// FileResult is the validation result of a file
+type FileResult struct {
+	Path   string
+	Tables []TableResult
+	Err    error
+	// Source find the lines of the elements, it is nil in the stream mode
+	Source *tableparser.SourceMap
+}
+
+// line return the line of the element of the diagnostic in the file, 0 when it is unknown
+func (r FileResult) line(diag *tableparser.Diagnostic) int {
+	if r.Source == nil || diag.Selection == nil {
+		return 0
+	}
+	var start, _, ok = r.Source.NearestStartTag(diag.Selection)
+	if ok == false {
+		return 0
+	}
+	return start.Line
+}
+
+// location return the path of the file followed by the line of the diagnostic when it is known
+func (r FileResult) location(diag *tableparser.Diagnostic) string {
+	if line := r.line(diag); line > 0 {
+		return r.Path + ":" + strconv.Itoa(line)
+	}
+	return r.Path
+}
+
 func main() {
-	fmt.Println("Start!")
-	var errs = []error{}
-	var filePath = "table.html"
-	// var filePath string
-	// fmt.Print("Input the html table file path: ")
-	// fmt.Scanln(&filePath)
-
-	htmlstring, err := ioutil.ReadFile(filePath)
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(htmlstring)))
-	doc.Find("table").EachWithBreak(func(index int, element *goquery.Selection) bool {
-		err = tableparser.Init(element)
+	// The subcommands have their own flags
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lsp" {
+		runLSP(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "watch" {
+		runWatch(os.Args[2:])
+		return
+	}
+
+	var configPath = flag.String("config", "", "configuration file, .tablevalidator.yaml or .tablevalidator.json in the working directory by default")
+	var format = flag.String("format", "", "output format: text, json or html")
+	var showSuppressed = flag.Bool("show-suppressed", false, "report the suppressed problems")
+	var baselinePath = flag.String("baseline", "", "baseline file of the known problems, it is recorded when it does not exist")
+	var groupBy = flag.String("group-by", "", "group the text report by: file or criterion")
+	var wcagSummary = flag.Bool("wcag-summary", false, "write the pass or fail status of each WCAG success criterion per page")
+	var grid = flag.Bool("grid", true, "draw the table grid under each problem of the text report")
+	var selector = flag.String("selector", "", "css selector of the validated tables, all the tables by default")
+	var timeout = flag.Duration("timeout", 0, "stop the validation after this duration, like 30s")
+	var stream = flag.Bool("stream", false, "read the files with the html tokenizer and validate one table at a time, for the very large files")
+	var lang = flag.String("lang", "", "language of the messages: en or fr")
+	var lenient = flag.Bool("lenient", false, "ignore the script-supporting, form and hidden input elements in the table structure")
+	var updateBaseline = flag.Bool("update-baseline", false, "record the baseline file again with the current problems")
+	flag.Parse()
+
+	var config = defaultConfig()
+	if len(*configPath) == 0 {
+		*configPath = findConfig(".")
+	}
+	if len(*configPath) != 0 {
+		var err error
+		config, err = loadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if len(*format) != 0 {
+		config.Format = *format
+	}
+	if len(*baselinePath) != 0 {
+		config.Baseline = *baselinePath
+	}
+	if len(*selector) != 0 {
+		config.Selector = *selector
+	}
+	if len(*lang) != 0 {
+		config.Lang = *lang
+	}
+	if *lenient {
+		config.Parser.Lenient = true
+	}
+	if len(*groupBy) != 0 {
+		config.GroupBy = *groupBy
+	}
+	if err := config.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := config.loadMessages(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Without file, this is the interactive mode on the table.html file
+	var interactive = flag.NArg() == 0
+	var paths = flag.Args()
+	if interactive {
+		fmt.Println("Start!")
+		paths = []string{defaultFilePath}
+	}
+
+	files, err := collectFiles(paths, config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var ctx = context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var opts = reportOptions{
+		ShowSuppressed: *showSuppressed,
+		GroupBy:        config.GroupBy,
+		Grid:           *grid,
+		Color:          isTerminal(os.Stdout),
+	}
+
+	var results = []FileResult{}
+	if *stream {
+		// The problems are written as soon as each table is parsed
+		results, err = streamFiles(ctx, os.Stdout, files, config, opts, *updateBaseline)
 		if err != nil {
-			errs = append(errs, err)
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
 		}
-		return true
-	})
+	} else {
+		for _, path := range files {
+			results = append(results, validateFile(ctx, path, config))
+		}
+
+		if len(config.Baseline) != 0 {
+			known, err := applyBaseline(config.Baseline, *updateBaseline, results)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			writeKnownProblems(known, config.Baseline)
+		}
+
+		err = writeReport(os.Stdout, config.Format, results, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	if *wcagSummary {
+		// Keep the json report valid, the summary is written on the error output
+		var output = os.Stdout
+		if config.Format == "json" {
+			output = os.Stderr
+		}
+		writeCriteriaSummary(output, results, config.failSeverity())
+	}
 
-	for _, err := range errs {
-		fmt.Println(err)
+	if interactive {
+		fmt.Printf("\nPress Enter Key to exit...")
+		fmt.Scanln()
 	}
 
-	fmt.Printf("\nPress Enter Key to exit...")
-	fmt.Scanln()
+	if hasFailure(results, config.failSeverity()) {
+		os.Exit(1)
+	}
+}
+
+// collectFiles return the files to validate, the html files are searched inside the directories
+func collectFiles(paths []string, config Config) ([]string, error) {
+	var files = []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() == false {
+			if config.isIgnored(path) == false {
+				files = append(files, path)
+			}
+			continue
+		}
+
+		err = filepath.Walk(path, func(filePath string, fileInfo os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if config.isIgnored(filePath) {
+				if fileInfo.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if fileInfo.IsDir() == false && isValidatedFile(filePath) {
+				files = append(files, filePath)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// validateFile parse each table of the file matched by the selector, the markdown and template files
+// are converted to html by their adapter
+func validateFile(ctx context.Context, path string, config Config) FileResult {
+	var result = FileResult{
+		Path:   path,
+		Tables: []TableResult{},
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	var source = adaptSource(path, string(content), config)
+	sourceMap, root, err := tableparser.NewSourceMapReader(strings.NewReader(source))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Source = sourceMap
+
+	tables, err := tableparser.ParseNodeContext(ctx, root, config.parserOptions(), config.Selector)
+	if err != nil {
+		result.Err = err
+	}
+
+	for _, table := range tables {
+		result.Tables = append(result.Tables, TableResult{
+			Index:       table.Index,
+			Fingerprint: table.Table.Fingerprint,
+			Caption:     table.Table.Caption,
+			Diagnostics: table.Table.Diagnostics,
+			Suppressed:  table.Table.Suppressed,
+			Table:       table.Table,
+		})
+	}
+
+	return result
+}
+
+// hasFailure check if a file can not be read or if a diagnostic reach the fail severity
+func hasFailure(results []FileResult, failSeverity tableparser.Severity) bool {
+	for _, result := range results {
+		if result.Err != nil {
+			return true
+		}
+		for _, table := range result.Tables {
+			for _, diag := range table.Diagnostics {
+				if diag.Severity >= failSeverity {
+					return true
+				}
+			}
+		}
+	}
+	return false
 }
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Default delay without change before a file is validated again, an editor can write a file several times on save
+const defaultDebounce = 200 * time.Millisecond
+
+// watchFinding is a problem of a watched file, the findings are compared by their key
+// so a problem is not reported again when an other table is added before his table
+type watchFinding struct {
+	key  string
+	line string
+}
+
+// watcher validate the html, markdown and template files again when they change and write the new and the fixed problems
+type watcher struct {
+	config   Config
+	roots    []string
+	debounce time.Duration
+	output   io.Writer
+	findings map[string][]watchFinding
+	notify   *fsnotify.Watcher
+}
+
+// runWatch is the watch subcommand
+func runWatch(args []string) {
+	var flags = flag.NewFlagSet("watch", flag.ExitOnError)
+	var configPath = flags.String("config", "", "configuration file, .tablevalidator.yaml or .tablevalidator.json in the working directory by default")
+	var debounce = flags.Duration("debounce", defaultDebounce, "delay without change before a file is validated again")
+	var selector = flags.String("selector", "", "css selector of the validated tables, all the tables by default")
+	var lang = flags.String("lang", "", "language of the messages: en or fr")
+	flags.Parse(args)
+
+	var config = defaultConfig()
+	if len(*configPath) == 0 {
+		*configPath = findConfig(".")
+	}
+	if len(*configPath) != 0 {
+		var err error
+		config, err = loadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if len(*selector) != 0 {
+		config.Selector = *selector
+	}
+	if len(*lang) != 0 {
+		config.Lang = *lang
+	}
+	if err := config.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := config.loadMessages(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Without path, the working directory is watched
+	var roots = flags.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer notify.Close()
+
+	var w = &watcher{
+		config:   config,
+		roots:    roots,
+		debounce: *debounce,
+		output:   os.Stdout,
+		findings: map[string][]watchFinding{},
+		notify:   notify,
+	}
+	if err := w.run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run validate all the files, then validate the changed files until the interrupt signal
+func (w *watcher) run() error {
+	for _, root := range w.roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			err = w.addDirectories(root)
+		} else {
+			// fsnotify watch the directories, the events of the other files are ignored
+			err = w.notify.Add(filepath.Dir(root))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	files, err := collectFiles(w.roots, w.config)
+	if err != nil {
+		return err
+	}
+	var count = 0
+	for _, path := range files {
+		count += w.validate(path, false)
+	}
+	fmt.Fprintf(w.output, "%d problems in %d files, watching for changes\n", count, len(files))
+
+	var interrupt = make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	// The changed files are validated once there is no change during the debounce delay
+	var pending = map[string]bool{}
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.notify.Events:
+			if ok == false {
+				return nil
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && w.config.isIgnored(event.Name) == false {
+					// A new directory is watched, and the files already copied in it are validated
+					w.addDirectories(event.Name)
+					if newFiles, err := collectFiles([]string{event.Name}, w.config); err == nil {
+						for _, path := range newFiles {
+							pending[filepath.Clean(path)] = true
+						}
+					}
+				}
+			}
+			if w.isWatched(event.Name) == false {
+				continue
+			}
+			pending[filepath.Clean(event.Name)] = true
+			timer = resetTimer(timer, w.debounce)
+			timeout = timer.C
+
+		case err, ok := <-w.notify.Errors:
+			if ok == false {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, err)
+
+		case <-timeout:
+			timeout = nil
+			var paths = []string{}
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			pending = map[string]bool{}
+			for _, path := range paths {
+				w.validate(path, true)
+			}
+
+		case <-interrupt:
+			return nil
+		}
+	}
+}
+
+// resetTimer start the timer again with the delay, the timer is created when it is nil.
+// The channel is drained when the timer fired but his time was not received,
+// otherwise the files would be validated before the end of the new delay
+func resetTimer(timer *time.Timer, delay time.Duration) *time.Timer {
+	if timer == nil {
+		return time.NewTimer(delay)
+	}
+	if timer.Stop() == false {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(delay)
+	return timer
+}
+
+// addDirectories watch the directory and his sub directories that are not ignored
+func (w *watcher) addDirectories(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() == false {
+			return nil
+		}
+		if path != root && w.config.isIgnored(path) {
+			return filepath.SkipDir
+		}
+		return w.notify.Add(path)
+	})
+}
+
+// isWatched check if the file is an html file of the watched paths
+func (w *watcher) isWatched(path string) bool {
+	if isValidatedFile(path) == false || w.config.isIgnored(path) {
+		return false
+	}
+
+	var clean = filepath.Clean(path)
+	for _, root := range w.roots {
+		var cleanRoot = filepath.Clean(root)
+		if clean == cleanRoot {
+			return true
+		}
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			if rel, err := filepath.Rel(cleanRoot, clean); err == nil && strings.HasPrefix(rel, "..") == false {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validate validate the file again and write the problems that are new or fixed since the last run,
+// it return the number of problems of the file
+func (w *watcher) validate(path string, changed bool) int {
+	path = filepath.Clean(path)
+
+	// A removed file has no problem anymore
+	var current = []watchFinding{}
+	if _, err := os.Stat(path); err == nil {
+		current = fileFindings(validateFile(context.Background(), path, w.config))
+	}
+
+	var added, fixed = diffFindings(w.findings[path], current)
+	if len(current) == 0 {
+		delete(w.findings, path)
+	} else {
+		w.findings[path] = current
+	}
+
+	if changed {
+		var status = "no change"
+		if len(added) > 0 || len(fixed) > 0 {
+			status = fmt.Sprintf("%d new, %d fixed", len(added), len(fixed))
+		}
+		fmt.Fprintf(w.output, "%s %s: %s, %d problems\n", time.Now().Format("15:04:05"), path, status, len(current))
+	}
+	for _, finding := range fixed {
+		fmt.Fprintf(w.output, "- %s\n", finding.line)
+	}
+	for _, finding := range added {
+		fmt.Fprintf(w.output, "+ %s\n", finding.line)
+	}
+	return len(current)
+}
+
+// fileFindings return the problems of the file, a problem is identified by his table fingerprint and his message
+func fileFindings(result FileResult) []watchFinding {
+	var findings = []watchFinding{}
+	if result.Err != nil {
+		findings = append(findings, watchFinding{
+			key:  "error\t" + result.Err.Error(),
+			line: fmt.Sprintf("%s: %v", result.Path, result.Err),
+		})
+	}
+	for _, table := range result.Tables {
+		for _, diag := range table.Diagnostics {
+			findings = append(findings, watchFinding{
+				key:  table.Fingerprint + "\t" + diag.Error(),
+				line: fmt.Sprintf("%s: table %d [%s]: %v", result.location(diag), table.Index, table.Fingerprint, diag),
+			})
+		}
+	}
+	return findings
+}
+
+// diffFindings return the current findings that are not in the previous findings, and the previous findings
+// that are not in the current findings. The same problem can be found several times in a table
+func diffFindings(previous []watchFinding, current []watchFinding) (added []watchFinding, fixed []watchFinding) {
+	var counts = map[string]int{}
+	for _, finding := range previous {
+		counts[finding.key]++
+	}
+	for _, finding := range current {
+		if counts[finding.key] > 0 {
+			counts[finding.key]--
+			continue
+		}
+		added = append(added, finding)
+	}
+
+	var remaining = map[string]int{}
+	for _, finding := range current {
+		remaining[finding.key]++
+	}
+	for _, finding := range previous {
+		if remaining[finding.key] > 0 {
+			remaining[finding.key]--
+			continue
+		}
+		fixed = append(fixed, finding)
+	}
+	return added, fixed
+}
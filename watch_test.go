@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// findingKeys return the keys of the findings
+func findingKeys(findings []watchFinding) []string {
+	var keys = []string{}
+	for _, finding := range findings {
+		keys = append(keys, finding.key)
+	}
+	return keys
+}
+
+func TestDiffFindings(t *testing.T) {
+	var a = watchFinding{key: "t1\ta"}
+	var b = watchFinding{key: "t1\tb"}
+	var c = watchFinding{key: "t2\ta"}
+
+	var tests = []struct {
+		name     string
+		previous []watchFinding
+		current  []watchFinding
+		added    []string
+		fixed    []string
+	}{
+		{"no change", []watchFinding{a, b}, []watchFinding{b, a}, []string{}, []string{}},
+		{"new problem", []watchFinding{a}, []watchFinding{a, c}, []string{"t2\ta"}, []string{}},
+		{"fixed problem", []watchFinding{a, c}, []watchFinding{c}, []string{}, []string{"t1\ta"}},
+		// The same problem can be found several times in a table, each one is counted
+		{"duplicate added", []watchFinding{a}, []watchFinding{a, a, b}, []string{"t1\ta", "t1\tb"}, []string{}},
+		{"duplicate fixed", []watchFinding{a, a, a}, []watchFinding{a}, []string{}, []string{"t1\ta", "t1\ta"}},
+		{"removed file", []watchFinding{a, b, b}, []watchFinding{}, []string{}, []string{"t1\ta", "t1\tb", "t1\tb"}},
+		{"new file", nil, []watchFinding{a}, []string{"t1\ta"}, []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var added, fixed = diffFindings(test.previous, test.current)
+			if keys := findingKeys(added); reflect.DeepEqual(keys, test.added) == false {
+				t.Errorf("added = %q, want %q", keys, test.added)
+			}
+			if keys := findingKeys(fixed); reflect.DeepEqual(keys, test.fixed) == false {
+				t.Errorf("fixed = %q, want %q", keys, test.fixed)
+			}
+		})
+	}
+}
+
+// The problems of a removed file are written as fixed and are forgotten
+func TestWatchRemovedFile(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "removed.html")
+	var output = bytes.Buffer{}
+	var w = &watcher{
+		config: defaultConfig(),
+		output: &output,
+		findings: map[string][]watchFinding{
+			path: {{key: "t1\ta", line: "problem a"}, {key: "t1\tb", line: "problem b"}},
+		},
+	}
+
+	if count := w.validate(path, true); count != 0 {
+		t.Errorf("problems of the removed file = %d, want 0", count)
+	}
+	if _, exists := w.findings[path]; exists == true {
+		t.Errorf("the findings of the removed file are kept: %v", w.findings[path])
+	}
+	if strings.Contains(output.String(), "0 new, 2 fixed, 0 problems\n- problem a\n- problem b\n") == false {
+		t.Errorf("output = %q", output.String())
+	}
+}
+
+// The timer is reset when it fired but his time was not received, the files are validated after the new delay.
+// The drain is needed before go 1.23, the later versions discard the time not received on Reset
+func TestResetTimer(t *testing.T) {
+	var timer = resetTimer(nil, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	timer = resetTimer(timer, 200*time.Millisecond)
+	select {
+	case <-timer.C:
+		t.Fatal("the time of the previous delay was received")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	select {
+	case <-timer.C:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the timer did not fire after the new delay")
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+// writeCriterionReport write the diagnostics grouped by WCAG success criterion,
+// the rules without criterion are written at the end
+func writeCriterionReport(w io.Writer, results []FileResult, showSuppressed bool) {
+	var ids = []string{}
+	for _, criterion := range tableparser.Criteria() {
+		ids = append(ids, criterion.ID)
+	}
+	ids = append(ids, "")
+
+	for _, id := range ids {
+		var lines = []string{}
+		for _, result := range results {
+			for _, table := range result.Tables {
+				for _, diag := range table.Diagnostics {
+					if hasCriterion(diag.Rule, id) {
+						lines = append(lines, fmt.Sprintf("%s: table %d [%s]: %v (%s)", result.Path, table.Index, table.Fingerprint, diag, strings.Join(diag.Rule.Techniques, ", ")))
+					}
+				}
+				if showSuppressed {
+					for _, diag := range table.Suppressed {
+						if hasCriterion(diag.Rule, id) {
+							lines = append(lines, fmt.Sprintf("%s: table %d [%s]: %v (%s) (suppressed)", result.Path, table.Index, table.Fingerprint, diag, strings.Join(diag.Rule.Techniques, ", ")))
+						}
+					}
+				}
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		if len(id) == 0 {
+			fmt.Fprintln(w, "Without success criterion")
+		} else {
+			var criterion, _ = tableparser.CriterionByID(id)
+			fmt.Fprintf(w, "WCAG %s %s (level %s)\n", criterion.ID, criterion.Name, criterion.Level)
+		}
+		for _, line := range lines {
+			fmt.Fprintln(w, "  "+line)
+		}
+	}
+
+	// The files that can not be read are not related to a criterion
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(w, "%s: %v\n", result.Path, result.Err)
+		}
+	}
+}
+
+// hasCriterion check if the rule is mapped to the criterion, an empty id match the rules without criterion
+func hasCriterion(rule tableparser.Rule, id string) bool {
+	if len(id) == 0 {
+		return len(rule.Criteria) == 0
+	}
+	for _, criterion := range rule.Criteria {
+		if criterion == id {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCriteriaSummary write the pass or fail status of each WCAG success criterion for each page,
+// a criterion fail when one of his problems reach the fail severity. A criterion with warnings
+// under the fail severity pass with warnings, only the info notes are not counted
+func writeCriteriaSummary(w io.Writer, results []FileResult, failSeverity tableparser.Severity) {
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s:\n", result.Path)
+		for _, criterion := range tableparser.Criteria() {
+			var failures = 0
+			var warnings = 0
+			for _, table := range result.Tables {
+				for _, diag := range table.Diagnostics {
+					if hasCriterion(diag.Rule, criterion.ID) == false {
+						continue
+					}
+					if diag.Severity >= failSeverity {
+						failures++
+					} else if diag.Severity >= tableparser.SeverityWarning {
+						warnings++
+					}
+				}
+			}
+
+			if failures > 0 {
+				fmt.Fprintf(w, "  fail  WCAG %s %s (%d problems)\n", criterion.ID, criterion.Name, failures)
+			} else if warnings > 0 {
+				fmt.Fprintf(w, "  pass with warnings  WCAG %s %s (%d problems)\n", criterion.ID, criterion.Name, warnings)
+			} else {
+				fmt.Fprintf(w, "  pass  WCAG %s %s\n", criterion.ID, criterion.Name)
+			}
+		}
+	}
+}
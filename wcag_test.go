@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/quycao/gotablevalidator/tableparser"
+)
+
+func TestCriteriaSummary(t *testing.T) {
+	var diagnostic = func(code int, severity tableparser.Severity) *tableparser.Diagnostic {
+		var rule, _ = tableparser.RuleByCode(code)
+		return &tableparser.Diagnostic{Rule: rule, Severity: severity}
+	}
+
+	var tests = []struct {
+		name        string
+		diagnostics tableparser.Diagnostics
+		failOn      tableparser.Severity
+		want        string
+	}{
+		{"no problem", tableparser.Diagnostics{}, tableparser.SeverityError, "  pass  WCAG 1.3.1"},
+		{"info note", tableparser.Diagnostics{diagnostic(43, tableparser.SeverityInfo), diagnostic(16, tableparser.SeverityInfo)}, tableparser.SeverityError, "  pass  WCAG 1.3.1"},
+		{"warnings", tableparser.Diagnostics{diagnostic(16, tableparser.SeverityWarning), diagnostic(38, tableparser.SeverityWarning)}, tableparser.SeverityError, "  pass with warnings  WCAG 1.3.1 Info and Relationships (2 problems)"},
+		{"error", tableparser.Diagnostics{diagnostic(16, tableparser.SeverityError), diagnostic(38, tableparser.SeverityWarning)}, tableparser.SeverityError, "  fail  WCAG 1.3.1 Info and Relationships (1 problems)"},
+		{"fail on warning", tableparser.Diagnostics{diagnostic(38, tableparser.SeverityWarning)}, tableparser.SeverityWarning, "  fail  WCAG 1.3.1 Info and Relationships (1 problems)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var results = []FileResult{{
+				Path:   "page.html",
+				Tables: []TableResult{{Index: 1, Diagnostics: test.diagnostics}},
+			}}
+			var output = bytes.Buffer{}
+			writeCriteriaSummary(&output, results, test.failOn)
+			if strings.Contains(output.String(), test.want) == false {
+				t.Errorf("summary =\n%s\nwant %q", output.String(), test.want)
+			}
+		})
+	}
+}